@@ -1,6 +1,12 @@
 package path
 
 import (
+	"context"
+	"iter"
+	"os"
+	"path/filepath"
+
+	"github.com/viocha/go-pathlib/internal/common"
 	"github.com/viocha/go-pathlib/purepath"
 )
 
@@ -8,6 +14,9 @@ import (
 type IBasePath interface {
 	purepath.IBasePurePath
 
+	Equal(other IPath) bool
+	Compare(other IPath) int
+
 	Parents() []IPath
 	Parent() IPath
 	Join(segments ...string) IPath
@@ -23,6 +32,9 @@ type IBasePath interface {
 
 	ToValid() IPath
 
+	IsAncestorOf(other IPath) bool
+	IsDescendantOf(other IPath) bool
+	CommonAncestor(others ...IPath) (IPath, error)
 	IsRelTo(other IPath, walkUp ...bool) bool
 	RelTo(other IPath, walkUp ...bool) (IPath, error)
 	RelToFile(other IPath, walkUp ...bool) (IPath, error)
@@ -34,6 +46,7 @@ type IBasePath interface {
 	MustWithSuffix(suffix string) IPath
 	MustRelTo(other IPath, walkUp ...bool) IPath
 	MustRelToFile(other IPath, walkUp ...bool) IPath
+	MustCommonAncestor(others ...IPath) IPath
 }
 
 // 将使用 IPurePath 的方法转换为使用 IPath 的方法
@@ -50,6 +63,17 @@ func NewBasePath(segments ...string) *BasePath {
 	}
 }
 
+func (p *BasePath) Equal(other IPath) bool {
+	return other != nil && p.IPurePath.Equal(other.ToPurePath())
+}
+
+func (p *BasePath) Compare(other IPath) int {
+	if other == nil { // 和 Equal 一致，nil排在所有路径之前
+		return 1
+	}
+	return p.IPurePath.Compare(other.ToPurePath())
+}
+
 func (p *BasePath) Parents() []IPath {
 	parents := p.IPurePath.Parents()
 	result := make([]IPath, len(parents))
@@ -117,6 +141,19 @@ func (p *BasePath) ToValid() IPath {
 	return FromPurePath(validPath)
 }
 
+func (p *BasePath) IsAncestorOf(other IPath) bool {
+	return p.IPurePath.IsAncestorOf(other.ToPurePath())
+}
+
+func (p *BasePath) IsDescendantOf(other IPath) bool {
+	return p.IPurePath.IsDescendantOf(other.ToPurePath())
+}
+
+func (p *BasePath) CommonAncestor(others ...IPath) (IPath, error) {
+	ancestor, err := p.IPurePath.CommonAncestor(toPurePaths(others)...)
+	return FromPurePath(ancestor), err
+}
+
 func (p *BasePath) IsRelTo(other IPath, walkUp ...bool) bool {
 	return p.IPurePath.IsRelTo(other.ToPurePath(), walkUp...)
 }
@@ -158,3 +195,550 @@ func (p *BasePath) MustRelTo(other IPath, walkUp ...bool) IPath {
 func (p *BasePath) MustRelToFile(other IPath, walkUp ...bool) IPath {
 	return FromPurePath(p.IPurePath.MustRelToFile(other.ToPurePath(), walkUp...))
 }
+
+func (p *BasePath) MustCommonAncestor(others ...IPath) IPath {
+	return FromPurePath(p.IPurePath.MustCommonAncestor(toPurePaths(others)...))
+}
+
+func toPurePaths(paths []IPath) []purepath.IPurePath {
+	result := make([]purepath.IPurePath, len(paths))
+	for i, path := range paths {
+		result[i] = path.ToPurePath()
+	}
+	return result
+}
+
+// ======================== 文件系统操作 ========================
+
+func (p *BasePath) ToPurePath() purepath.IPurePath {
+	return p.IPurePath
+}
+
+func (p *BasePath) ToAbs() (IPath, error) {
+	absPath, err := filepath.Abs(p.String())
+	if err != nil {
+		return nil, common.WrapSub(err, ErrToAbs, "failed to convert to absolute path: %q", p)
+	}
+	return p.newPath(absPath), nil
+}
+
+// 读取符号链接的目标路径
+func (p *BasePath) ReadLink() (IPath, error) {
+	target, err := os.Readlink(p.String())
+	if err != nil {
+		return nil, common.WrapSub(err, ErrReadLink, "failed to read symlink: %q", p)
+	}
+	return p.newPath(target), nil
+}
+
+func (p *BasePath) ReadLinkPath() (IPath, error) {
+	// 读取符号链接的目标路径
+	target, err := p.ReadLink()
+	if err != nil {
+		return nil, err
+	}
+	// 如果是相对路径，和链接路径进行拼接
+	if !target.IsAbs() {
+		target = p.JoinPathForFile(target)
+	}
+	return target, nil
+}
+
+// 判断路径是否存在，默认跟随符号链接
+func (p *BasePath) Exists(follow ...bool) bool {
+	isFollow := true
+	if len(follow) > 0 {
+		isFollow = follow[0]
+	}
+	if isFollow {
+		_, err := p.Stat()
+		return err == nil
+	} else {
+		_, err := p.Lstat()
+		return err == nil
+	}
+}
+
+// 返回文件的状态信息，会跟随目标的符号链接
+func (p *BasePath) Stat() (os.FileInfo, error) {
+	info, err := os.Stat(p.String())
+	if err != nil {
+		return nil, common.WrapSub(err, ErrReadStat, "failed to read file status: %q", p)
+	}
+	return info, nil
+}
+
+// 返回文件的状态信息，不会跟随目标的符号链接
+func (p *BasePath) Lstat() (os.FileInfo, error) {
+	info, err := os.Lstat(p.String())
+	if err != nil {
+		return nil, common.WrapSub(err, ErrReadLstat, "failed to read file status without following symlink: %q", p)
+	}
+	return info, nil
+}
+
+// 如果 follow 为 true，则跟目标文件的符号链接，中间的符号链接始终会被跟随
+func (p *BasePath) IsFile(follow ...bool) bool {
+	isFollow := common.ParseOptional(follow, true) // 默认跟随符号链接
+	if isFollow {
+		stat, err := p.Stat()
+		return err == nil && stat.Mode().IsRegular()
+	} else {
+		stat, err := p.Lstat()
+		return err == nil && stat.Mode().IsRegular()
+	}
+}
+
+// 如果 follow 为 true，则跟目标文件的符号链接，中间的符号链接始终会被跟随
+func (p *BasePath) IsDir(follow ...bool) bool {
+	isFollow := common.ParseOptional(follow, true) // 默认跟随符号链接
+	if isFollow {
+		stat, err := p.Stat()
+		return err == nil && stat.IsDir()
+	} else {
+		stat, err := p.Lstat()
+		return err == nil && stat.IsDir()
+	}
+}
+
+// 判断路径是否是符号链接
+func (p *BasePath) IsLink() bool {
+	info, err := p.Lstat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeSymlink != 0
+}
+
+// 判断两个路径是否指向同一个文件
+func (p *BasePath) SameFile(otherPath IPath) bool {
+	info1, err1 := p.Stat()
+	info2, err2 := otherPath.Stat()
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return os.SameFile(info1, info2)
+}
+
+// 打开文件，默认只读方式打开，故不存在默认返回错误。
+// 可以自行指定写入方式，比如附加，从开头覆写，不存在则创建，存在则清空等
+func (p *BasePath) Open(mode ...int) (*os.File, error) {
+	openMode := common.ParseOptional(mode, os.O_RDONLY) // 默认只读方式打开
+	file, err := os.OpenFile(p.String(), openMode, os.ModePerm)
+	if err != nil {
+		return nil, common.WrapSub(err, ErrOpen, "failed to open file %q with mode %d", p, openMode)
+	}
+	return file, nil
+}
+
+// 打开文件用于写入，文件不存在会自动创建，默认覆盖并清空已有内容，可以指定追加模式
+func (p *BasePath) OpenWrite(append ...bool) (*os.File, error) {
+	isAppend := common.ParseOptional(append, false) // 默认不追加内容
+	if !p.Exists() {                                // 如果文件不存在，直接创建新文件
+		if err := p.Create(); err != nil {
+			return nil, err
+		}
+	}
+	mode := os.O_WRONLY | os.O_TRUNC // 默认覆盖并清空已有内容
+	if isAppend {
+		mode = os.O_WRONLY | os.O_APPEND // 追加模式
+	}
+	file, err := os.OpenFile(p.String(), mode, os.ModePerm)
+	if err != nil {
+		return nil, common.WrapSub(err, ErrOpen, "failed to open file %q for writing with append=%v", p, isAppend)
+	}
+	return file, nil
+}
+
+// 读取文件内容，返回字符串
+func (p *BasePath) Read(encoding ...string) (string, error) {
+	if len(encoding) > 0 {
+		panic("encoding is not implemented yet")
+	}
+	content, err := os.ReadFile(p.String())
+	if err != nil {
+		return "", common.WrapSub(err, ErrRead, "failed to read file: %q", p)
+	}
+	return string(content), nil
+}
+
+// 将字符串写入文件，会先清空文件内容
+func (p *BasePath) Write(text string, encoding ...string) error {
+	if len(encoding) > 0 {
+		panic("encoding is not implemented yet")
+	}
+	if err := p.Create(); err != nil {
+		return err
+	}
+	err := os.WriteFile(p.String(), []byte(text), os.ModePerm)
+	if err != nil {
+		return common.WrapSub(err, ErrWrite, "failed to write file: %q", p)
+	}
+	return nil
+}
+
+// 读取文件内容为字节切片
+func (p *BasePath) ReadBytes() ([]byte, error) {
+	content, err := os.ReadFile(p.String())
+	if err != nil {
+		return nil, common.WrapSub(err, ErrRead, "failed to read file: %q", p)
+	}
+	return content, nil
+}
+
+// 将字节切片写入文件
+func (p *BasePath) WriteBytes(data []byte) error {
+	if err := p.Create(); err != nil {
+		return err
+	}
+	err := os.WriteFile(p.String(), data, os.ModePerm)
+	if err != nil {
+		return common.WrapSub(err, ErrWrite, "failed to write file: %q", p)
+	}
+	return nil
+}
+
+// 创建文件，或者清空文件内容
+func (p *BasePath) Create(parents ...bool) error {
+	createParents := common.ParseOptional(parents, true) // 默认创建父目录
+
+	if createParents {
+		if err := p.Parent().EnsureDir(); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Create(p.String())
+	if err != nil {
+		return common.WrapSub(err, ErrCreate, "failed to create file: %q", p)
+	}
+	defer closeFile(file)
+	return nil
+}
+
+// 创建目录，默认会创建父目录
+func (p *BasePath) Mkdir(parents ...bool) error {
+	createParents := common.ParseOptional(parents, true) // 默认创建父目录
+	// 如果不创建父目录，直接创建当前目录
+	if createParents {
+		err := os.MkdirAll(p.String(), os.ModePerm)
+		if err != nil {
+			return common.WrapSub(err, ErrMkdir, "failed to create directory %q with parents=%v", p, createParents)
+		}
+		return nil
+	}
+	err := os.Mkdir(p.String(), os.ModePerm)
+	if err != nil {
+		return common.WrapSub(err, ErrMkdir, "failed to create directory %q without parents=%v", p, createParents)
+	}
+	return nil
+}
+
+// 创建符号链接
+func (p *BasePath) Symlink(target IPath, parents ...bool) error {
+	createParents := common.ParseOptional(parents, true) // 默认创建父目录
+
+	if createParents {
+		if err := p.Parent().EnsureDir(); err != nil {
+			return err
+		}
+	}
+
+	err := os.Symlink(target.String(), p.String())
+	if err != nil {
+		return common.WrapSub(err, ErrSymlink, "failed to create symlink from %q to %q", p, target)
+	}
+	return nil
+}
+
+// 确保文件存在，如果不存在则创建
+func (p *BasePath) EnsureFile() error {
+	if p.IsFile() {
+		return nil // 文件已存在
+	}
+	if p.Exists() { // 如果路径存在但不是文件，返回错误
+		return common.WrapMsg(ErrEnsureFile, "path exists but is not a file: %q", p)
+	}
+	return p.Create(true) // 创建文件并确保父目录存在
+}
+
+// 确保目录存在，如果不存在则创建
+func (p *BasePath) EnsureDir() error {
+	if p.IsDir() {
+		return nil // 目录已存在
+	}
+	if p.Exists() { // 如果路径存在但不是目录，返回错误
+		return common.WrapMsg(ErrEnsureDir, "path exists but is not a directory: %q", p)
+	}
+	err := p.Mkdir(true) // 创建目录并确保父目录存在
+	if err != nil {
+		return common.WrapSub(err, ErrEnsureDir, "directory : %q", p)
+	}
+	return nil
+}
+
+// 删除文件或目录，默认递归删除
+func (p *BasePath) Remove(recursive ...bool) error {
+	isRecursive := common.ParseOptional(recursive, true) // 默认递归删除
+
+	if !p.Exists(false) { // 不跟随符号链接，os.Remove只会删除链接本身
+		return nil // 如果路径不存在，直接返回 nil，静默成功
+	}
+
+	if isRecursive {
+		err := os.RemoveAll(p.String()) // 递归删除
+		if err != nil {
+			return common.WrapSub(err, ErrRemove, "failed to remove path %q recursively", p)
+		}
+		return nil // 成功删除
+	} else {
+		err := os.Remove(p.String()) // 非递归删除文件或目录，如果是目录且非空会返回错误
+		if err != nil {
+			return common.WrapSub(err, ErrRemove, "failed to remove path %q non-recursively", p)
+		}
+		return nil // 成功删除
+	}
+}
+
+// 重命名文件或目录
+func (p *BasePath) Rename(newName string, replace ...bool) (IPath, error) {
+	newPath, err := p.WithName(newName)
+	if err != nil {
+		return nil, common.WrapSub(err, ErrRename, "failed to create new path with name %q from %q", newName, p)
+	}
+	if err := p.Move(newPath, replace...); err != nil {
+		return nil, err
+	}
+	return newPath, nil
+}
+
+// 移动文件或目录到新路径
+func (p *BasePath) Move(dst IPath, replace ...bool) error {
+	return Move(p.toPath(), dst, replace...)
+}
+
+func (p *BasePath) Copy(dst IPath, copyOptions ...CopyOptions) error {
+	return Copy(p.toPath(), dst, copyOptions...)
+}
+
+func (p *BasePath) CopyContext(ctx context.Context, dst IPath, copyOptions ...CopyOptions) error {
+	return CopyContext(ctx, p.toPath(), dst, copyOptions...)
+}
+
+// p 必须是一个目录，dst 必须不存在，或者是一个目录。
+func (p *BasePath) CopyMerge(dst IPath, copyOptions ...CopyOptions) error {
+	return p.CopyMergeContext(context.Background(), dst, copyOptions...)
+}
+
+func (p *BasePath) CopyMergeContext(ctx context.Context, dst IPath, copyOptions ...CopyOptions) error {
+	if p.SameFile(dst) { // 如果源路径和目标路径相同，直接返回
+		return nil
+	}
+	if !p.IsDir(false) { // 如果源路径不是目录，返回错误
+		return common.WrapMsg(ErrCopyMerge, "source path %q is not a directory", p)
+	}
+	if err := dst.EnsureDir(); err != nil { // 确保目标目录存在
+		return err
+	}
+	return CopyMergeContext(ctx, p.toPath(), dst, copyOptions...)
+}
+
+func (p *BasePath) MoveMerge(dst IPath, copyOptions ...CopyOptions) error {
+	return p.MoveMergeContext(context.Background(), dst, copyOptions...)
+}
+
+// 取消时已经复制的路径会保留在dst中，p不会被删除。
+// 移动完成后会删除整个p，所以不支持Include、Exclude和Filter，否则没有移动的路径也会被删除
+func (p *BasePath) MoveMergeContext(ctx context.Context, dst IPath, copyOptions ...CopyOptions) error {
+	options := common.ParseOptional(copyOptions, CopyOptions{})
+	if len(options.Include) > 0 || len(options.Exclude) > 0 || options.Filter != nil {
+		return common.WrapMsg(ErrMove, "cannot move-merge %q with Include, Exclude or Filter", p)
+	}
+	if err := p.CopyMergeContext(ctx, dst, options); err != nil {
+		return err
+	}
+	if err := p.Remove(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// 读取目录内容，返回路径列表
+// 返回的每个路径都是 *Entry，缓存了目录项的类型
+func (p *BasePath) ReadDir() ([]IPath, error) {
+	entries, err := ReadDirEntries(p.toPath())
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]IPath, len(entries))
+	for i, entry := range entries {
+		paths[i] = entry
+	}
+	return paths, nil
+}
+
+func (p *BasePath) ReadDirEntries() ([]*Entry, error) {
+	return ReadDirEntries(p.toPath())
+}
+
+func (p *BasePath) Glob(pattern string, globOptions ...GlobOptions) ([]IPath, error) {
+	return Glob(p.toPath(), pattern, globOptions...)
+}
+
+func (p *BasePath) RGlob(pattern string, globOptions ...GlobOptions) ([]IPath, error) {
+	return RGlob(p.toPath(), pattern, globOptions...)
+}
+
+func (p *BasePath) GlobPattern(pattern *purepath.Pattern, globOptions ...GlobOptions) ([]IPath, error) {
+	return GlobPattern(p.toPath(), pattern, globOptions...)
+}
+
+func (p *BasePath) Walk(fn func(path IPath, err error) error, walkOptions ...WalkOptions) error {
+	return Walk(p.toPath(), fn, walkOptions...)
+}
+
+func (p *BasePath) IterDir() iter.Seq2[IPath, error] {
+	return IterDir(p.toPath())
+}
+
+func (p *BasePath) IterGlob(pattern string, globOptions ...GlobOptions) iter.Seq2[IPath, error] {
+	return IterGlob(p.toPath(), pattern, globOptions...)
+}
+
+func (p *BasePath) IterWalk(walkOptions ...WalkOptions) iter.Seq2[IPath, error] {
+	return IterWalk(p.toPath(), walkOptions...)
+}
+
+func (p *BasePath) WalkDirs(walkDirsOptions ...WalkDirsOptions) iter.Seq2[*WalkDirsEntry, error] {
+	return WalkDirs(p.toPath(), walkDirsOptions...)
+}
+
+// ======================== panic版本的方法 ========================
+
+// MustToAbs 返回绝对路径，如果失败则 panic
+func (p *BasePath) MustToAbs() IPath {
+	absPath, err := p.ToAbs()
+	if err != nil {
+		panic(err)
+	}
+	return absPath
+}
+
+// 返回符号链接的目标路径，如果失败则 panic
+func (p *BasePath) MustReadLink() IPath {
+	target, err := p.ReadLink()
+	if err != nil {
+		panic(err)
+	}
+	return target
+}
+
+func (p *BasePath) MustReadLinkPath() IPath {
+	target, err := p.ReadLinkPath()
+	if err != nil {
+		panic(err)
+	}
+	return target
+}
+
+func (p *BasePath) MustStat() os.FileInfo {
+	stat, err := p.Stat()
+	if err != nil {
+		panic(err)
+	}
+	return stat
+}
+
+func (p *BasePath) MustLStat() os.FileInfo {
+	stat, err := p.Lstat()
+	if err != nil {
+		panic(err)
+	}
+	return stat
+}
+
+// 打开文件，如果失败则 panic
+func (p *BasePath) MustOpen(mode ...int) *os.File {
+	file, err := p.Open(mode...)
+	if err != nil {
+		panic(err)
+	}
+	return file
+}
+
+// 打开文件用于写入，如果失败则 panic
+func (p *BasePath) MustOpenWrite(append ...bool) *os.File {
+	file, err := p.OpenWrite(append...)
+	if err != nil {
+		panic(err)
+	}
+	return file
+}
+
+// 读取文件内容，如果失败则 panic
+func (p *BasePath) MustRead() string {
+	content, err := p.Read()
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
+
+// 读取文件内容为字节切片，如果失败则 panic
+func (p *BasePath) MustReadBytes() []byte {
+	content, err := p.ReadBytes()
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
+
+func (p *BasePath) MustRename(newName string, replace ...bool) IPath {
+	newPath, err := p.Rename(newName, replace...)
+	if err != nil {
+		panic(err)
+	}
+	return newPath
+}
+
+func (p *BasePath) MustReadDir() []IPath {
+	paths, err := p.ReadDir()
+	if err != nil {
+		panic(err)
+	}
+	return paths
+}
+
+func (p *BasePath) MustGlob(pattern string, globOptions ...GlobOptions) []IPath {
+	paths, err := p.Glob(pattern, globOptions...)
+	if err != nil {
+		panic(err)
+	}
+	return paths
+}
+
+func (p *BasePath) MustRGlob(pattern string, globOptions ...GlobOptions) []IPath {
+	paths, err := p.RGlob(pattern, globOptions...)
+	if err != nil {
+		panic(err)
+	}
+	return paths
+}
+
+func (p *BasePath) MustGlobPattern(pattern *purepath.Pattern, globOptions ...GlobOptions) []IPath {
+	paths, err := p.GlobPattern(pattern, globOptions...)
+	if err != nil {
+		panic(err)
+	}
+	return paths
+}
+
+// 转换成和纯路径风格对应的 IPath，用于调用接收 IPath 的函数
+func (p *BasePath) toPath() IPath {
+	return FromPurePath(p.IPurePath)
+}
+
+// 使用和p相同的风格创建新的路径
+func (p *BasePath) newPath(segments ...string) IPath {
+	return FromPurePath(purepath.NewWithFlavour(p.Flavour(), segments...))
+}
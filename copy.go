@@ -0,0 +1,569 @@
+package path
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/viocha/go-pathlib/internal/common"
+	"github.com/viocha/go-pathlib/purepath"
+)
+
+const copyChunkSize = 8 << 20 // 每次复制的最大字节数，每一块之间检查是否取消，并报告进度
+
+// 复制的选项，默认只复制内容，类似 cp -a 的保留选项需要显式开启
+type CopyOptions struct {
+	Replace        bool      // 目标路径存在时先删除，默认返回错误，对 CopyMerge 无效
+	MergeMode      MergeMode // CopyMerge 中目标路径已经存在时的处理方式，默认返回错误，两个目录总是会递归合并
+	PreserveMode   bool      // 保留权限位，包括setuid、setgid和sticky，不包括符号链接
+	PreserveTimes  bool      // 保留修改时间，不包括符号链接
+	PreserveOwner  bool      // 保留所有者和所属组，通常需要root权限，只支持类Unix系统
+	PreserveXattrs bool      // 保留扩展属性，只支持Linux，不包括符号链接
+	// 过滤目录中的子路径，模式从右侧匹配相对于源目录的路径，例如 *.tmp 会匹配任意层级的 .tmp 文件，
+	// 被排除的目录不会向下遍历，合并时目标中对应的路径保持不变，MoveMerge 不支持过滤
+	Include []string                               // 只复制匹配任意一个模式的文件，目录总是会进入，为空表示复制所有文件
+	Exclude []string                               // 不复制匹配任意一个模式的文件和目录，例如 **/.git
+	Filter  func(src IPath, info fs.FileInfo) bool // 返回false时不复制这个路径，info为不跟随符号链接的信息
+	// MergeModeCallback 时决定冲突的处理方式，dst为已经存在并且不是目录的目标路径，返回错误时终止合并
+	OnConflict func(src, dst IPath) (MergeAction, error)
+	// 报告复制的进度，在每个文件开始、每复制一块内容以及每个文件完成时调用。
+	// 不为nil时会在复制之前遍历源路径，统计计划复制的总量
+	Progress func(progress CopyProgress)
+}
+
+// 复制的进度
+type CopyProgress struct {
+	TotalBytes int64 // 计划复制的字节数
+	TotalFiles int   // 计划复制的文件数，包括符号链接，不包括目录
+	Bytes      int64 // 已经复制的字节数
+	Files      int   // 已经完成的文件数，合并时跳过的文件也算作完成
+	Path       IPath // 正在复制的源路径
+}
+
+// 复制文件、符号链接或目录到新路径，目录会被递归复制
+func Copy(src, dst IPath, copyOptions ...CopyOptions) error {
+	return CopyContext(context.Background(), src, dst, copyOptions...)
+}
+
+// 和 Copy 相同，ctx取消时在两个文件之间或者两块内容之间终止复制，删除复制了一半的文件，并返回ctx的错误
+func CopyContext(ctx context.Context, src, dst IPath, copyOptions ...CopyOptions) error {
+	options := common.ParseOptional(copyOptions, CopyOptions{})
+	if src.SameFile(dst) { // 如果源路径和目标路径相同，直接返回
+		return nil
+	}
+	// 确保目标路径的父目录存在，以及目标路径不存在
+	if err := ensureMove(dst, options.Replace); err != nil {
+		return err
+	}
+	c, err := newCopier(ctx, options, src, false)
+	if err != nil {
+		return err
+	}
+	return c.copy(src, dst)
+}
+
+// src和dst必须是一个目录，使用 CopyOptions.MergeMode 处理冲突，按照选项保留的元数据也会复制到已经存在的目录
+func CopyMerge(src, dst IPath, copyOptions ...CopyOptions) error {
+	return CopyMergeContext(context.Background(), src, dst, copyOptions...)
+}
+
+// 和 CopyMerge 相同，可以通过ctx取消
+func CopyMergeContext(ctx context.Context, src, dst IPath, copyOptions ...CopyOptions) error {
+	c, err := newCopier(ctx, common.ParseOptional(copyOptions, CopyOptions{}), src, false)
+	if err != nil {
+		return err
+	}
+	return c.copyMerge(src, dst)
+}
+
+// 递归复制整个文件夹，目标文件夹必须不存在，且会自动创建父目录
+func CopyDir(src, dst IPath, copyOptions ...CopyOptions) error {
+	options := common.ParseOptional(copyOptions, CopyOptions{})
+	// 确保目标路径的父目录存在，以及目标路径不存在
+	if err := ensureMove(dst, options.Replace); err != nil {
+		return err
+	}
+	c, err := newCopier(context.Background(), options, src, false)
+	if err != nil {
+		return err
+	}
+	return c.copyDir(src, dst)
+}
+
+// 目标路径必须不存在，且会自动创建父目录
+func CopyFile(src, dst IPath, copyOptions ...CopyOptions) error {
+	options := common.ParseOptional(copyOptions, CopyOptions{})
+	if err := ensureMove(dst, options.Replace); err != nil {
+		return err
+	}
+	c, err := newCopier(context.Background(), options, src, true)
+	if err != nil {
+		return err
+	}
+	return c.copyFile(src, dst)
+}
+
+// 目标路径必须不存在，且会自动创建父目录
+func CopySymlink(src, dst IPath) error {
+	c := &copier{ctx: context.Background()}
+	return c.copySymlink(src, dst)
+}
+
+// 一次复制操作的状态，目标路径已经确认可以写入，不再处理 CopyOptions.Replace
+type copier struct {
+	ctx      context.Context
+	options  CopyOptions
+	progress CopyProgress
+	root     IPath               // 复制的源路径，Include和Exclude相对于这个路径匹配
+	include  []*purepath.Pattern // 编译后的 CopyOptions.Include
+	exclude  []*purepath.Pattern // 编译后的 CopyOptions.Exclude
+}
+
+// 创建copier，需要报告进度时统计src下计划复制的总量，followRoot表示src是符号链接时复制链接的目标
+func newCopier(ctx context.Context, options CopyOptions, src IPath, followRoot bool) (*copier, error) {
+	c := &copier{ctx: ctx, options: options, root: src}
+	var err error
+	if c.include, err = compileCopyPatterns(src, options.Include); err != nil {
+		return nil, err
+	}
+	if c.exclude, err = compileCopyPatterns(src, options.Exclude); err != nil {
+		return nil, err
+	}
+	if options.Progress == nil {
+		return c, nil
+	}
+	files, bytes, err := c.count(src, followRoot)
+	if err != nil {
+		return nil, err
+	}
+	c.progress = CopyProgress{TotalFiles: files, TotalBytes: bytes}
+	return c, nil
+}
+
+func compileCopyPatterns(root IPath, patterns []string) ([]*purepath.Pattern, error) {
+	compiled := make([]*purepath.Pattern, len(patterns))
+	for i, pattern := range patterns {
+		p, err := purepath.CompilePatternWithFlavour(root.Flavour(), pattern)
+		if err != nil {
+			return nil, err
+		}
+		if p.IsAnchored() {
+			return nil, common.WrapMsg(ErrGlobPattern, "copy filter pattern %q must be relative to %q", pattern, root)
+		}
+		compiled[i] = p
+	}
+	return compiled, nil
+}
+
+// 统计src下需要复制的文件数和字节数，不跟随符号链接，符号链接算作一个文件。
+// src本身不会被过滤，followRoot表示src是符号链接时统计链接的目标
+func (c *copier) count(src IPath, followRoot bool) (files int, bytes int64, err error) {
+	var info fs.FileInfo
+	if followRoot {
+		info, err = src.Stat()
+	} else {
+		info, err = src.Lstat()
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	if info.Mode().IsRegular() {
+		return 1, info.Size(), nil
+	} else if !info.IsDir() {
+		return 1, 0, nil
+	}
+	err = Walk(src, func(path IPath, err error) error {
+		if err != nil {
+			return err
+		}
+		included, err := c.included(path)
+		if err != nil {
+			return err
+		}
+		if !included {
+			return WalkSkip // 被排除的目录不会向下遍历
+		}
+		info, err := path.Lstat()
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			files++
+			bytes += info.Size()
+		} else if info.Mode()&fs.ModeSymlink != 0 {
+			files++
+		}
+		return nil
+	}, WalkOptions{MinDepth: 1})
+	return files, bytes, err
+}
+
+// 按照 Include、Exclude 和 Filter 判断是否复制目录中的子路径src
+func (c *copier) included(src IPath) (bool, error) {
+	if len(c.include) == 0 && len(c.exclude) == 0 && c.options.Filter == nil {
+		return true, nil
+	}
+	info, err := src.Lstat()
+	if err != nil {
+		return false, err
+	}
+	if len(c.include) > 0 || len(c.exclude) > 0 {
+		rel, err := src.RelTo(c.root)
+		if err != nil {
+			return false, err
+		}
+		relPath := rel.ToPurePath()
+		if slices.ContainsFunc(c.exclude, relPath.MatchPattern) {
+			return false, nil
+		}
+		if len(c.include) > 0 && !info.IsDir() && !slices.ContainsFunc(c.include, relPath.MatchPattern) {
+			return false, nil
+		}
+	}
+	return c.options.Filter == nil || c.options.Filter(src, info), nil
+}
+
+// ctx已经取消时返回ctx的错误
+func (c *copier) canceled() error {
+	if c.ctx.Err() != nil {
+		return context.Cause(c.ctx)
+	}
+	return nil
+}
+
+func (c *copier) report() {
+	if c.options.Progress != nil {
+		c.options.Progress(c.progress)
+	}
+}
+
+// 开始复制一个文件或符号链接
+func (c *copier) fileStart(src IPath) {
+	c.progress.Path = src
+	c.report()
+}
+
+// 一个文件或符号链接完成，bytes为这个文件中还没有计入的字节数，例如稀疏文件中的空洞
+func (c *copier) fileDone(bytes int64) {
+	c.progress.Bytes += bytes
+	c.progress.Files++
+	c.report()
+}
+
+// 根据src的类型复制到不存在的dst
+func (c *copier) copy(src, dst IPath) error {
+	if err := c.canceled(); err != nil {
+		return err
+	}
+	if src.IsLink() { // 先判断是否是符号链接
+		return c.copySymlink(src, dst)
+	} else if src.IsFile(false) {
+		return c.copyFile(src, dst)
+	} else if src.IsDir(false) {
+		return c.copyDir(src, dst)
+	}
+	// 不支持复制的类型
+	return common.WrapMsg(ErrCopy, "unsupported path type for copy: %q", src)
+}
+
+// src和dst必须是一个目录
+func (c *copier) copyMerge(src, dst IPath) error {
+	info, err := src.Lstat()
+	if err != nil {
+		return err
+	}
+	children, err := src.ReadDir()
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if err := c.canceled(); err != nil {
+			return err
+		}
+		included, err := c.included(child)
+		if err != nil {
+			return err
+		}
+		if !included { // 被过滤的路径，目录不会向下遍历
+			continue
+		}
+		name := child.Name()
+		childDst := dst.Join(name) // 将child 复制到 childDst
+		// 目标路径存在，尝试进行解决冲突
+		if childDst.Exists(false) {
+			if childDst.IsDir(false) { // 目标是目录
+				// 不允许使用文件覆盖目录
+				if !child.IsDir(false) {
+					return common.WrapMsg(ErrTargetExists, "target path %q is a directory but source path %q is not a directory",
+						childDst, child)
+				}
+				// 两个都是目录，递归合并，目录本身不算冲突
+				if err := c.copyMerge(child, childDst); err != nil {
+					return err
+				}
+				continue
+			}
+			action, err := c.resolveConflict(child, childDst)
+			if err != nil {
+				return err
+			}
+			switch action {
+			case MergeActionSkip: // 跳过冲突
+				if err := c.skip(child); err != nil {
+					return err
+				}
+				continue
+			case MergeActionReplace: // 替换冲突
+				if err := childDst.Remove(); err != nil { // 删除目标文件或链接
+					return err
+				}
+			case MergeActionKeepBoth: // 保留两者，使用新的名称复制
+				if childDst, err = keepBothPath(childDst); err != nil {
+					return err
+				}
+			default:
+				return common.WrapMsg(ErrCopyMerge, "unsupported merge action %d for %q", action, childDst)
+			}
+		}
+		// 目标路径不存在，直接复制
+		if err := c.copy(child, childDst); err != nil {
+			return err
+		}
+	}
+	return copyMetadata(src, dst, info, c.options)
+}
+
+// 根据 CopyOptions.MergeMode 决定目标路径已经存在时的处理方式，dst不是目录
+func (c *copier) resolveConflict(src, dst IPath) (MergeAction, error) {
+	switch mode := c.options.MergeMode; mode {
+	case MergeModeError:
+		return MergeActionSkip, common.WrapMsg(ErrTargetExists, "target path %q already exists, cannot merge", dst)
+	case MergeModeSkip:
+		return MergeActionSkip, nil
+	case MergeModeReplace:
+		return MergeActionReplace, nil
+	case MergeModeNewer, MergeModeLarger: // 比较不跟随符号链接的信息
+		srcInfo, err := src.Lstat()
+		if err != nil {
+			return MergeActionSkip, err
+		}
+		dstInfo, err := dst.Lstat()
+		if err != nil {
+			return MergeActionSkip, err
+		}
+		if mode == MergeModeNewer && srcInfo.ModTime().After(dstInfo.ModTime()) ||
+			mode == MergeModeLarger && srcInfo.Size() > dstInfo.Size() {
+			return MergeActionReplace, nil
+		}
+		return MergeActionSkip, nil
+	case MergeModeKeepBoth:
+		return MergeActionKeepBoth, nil
+	case MergeModeCallback:
+		if c.options.OnConflict == nil {
+			return MergeActionSkip, common.WrapMsg(ErrCopyMerge, "MergeModeCallback requires CopyOptions.OnConflict")
+		}
+		return c.options.OnConflict(src, dst)
+	default:
+		return MergeActionSkip, common.WrapMsg(ErrCopyMerge, "unsupported merge mode: %d", mode)
+	}
+}
+
+// 在dst旁边找一个不存在的路径，例如 name (1).ext、name (2).ext
+func keepBothPath(dst IPath) (IPath, error) {
+	stem, suffix := dst.Stem(), dst.Suffix()
+	for i := 1; ; i++ {
+		path, err := dst.WithName(fmt.Sprintf("%s (%d)%s", stem, i, suffix))
+		if err != nil {
+			return nil, err
+		}
+		if !path.Exists(false) {
+			return path, nil
+		}
+	}
+}
+
+// 跳过src，其中的文件都算作已经完成
+func (c *copier) skip(src IPath) error {
+	if c.options.Progress == nil {
+		return nil
+	}
+	files, bytes, err := c.count(src, false)
+	if err != nil {
+		return err
+	}
+	c.progress.Files += files
+	c.progress.Bytes += bytes
+	c.report()
+	return nil
+}
+
+// 递归复制整个文件夹到不存在的dst
+func (c *copier) copyDir(src, dst IPath) error {
+	if err := ensureMove(dst, false); err != nil {
+		return err
+	}
+	info, err := src.Lstat()
+	if err != nil {
+		return err
+	}
+	// 读取源目录的子目录和文件
+	children, err := src.ReadDir()
+	if err != nil {
+		return err
+	}
+	// 先创建目标目录，再拷贝子目录和文件
+	if err := dst.Mkdir(); err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := c.canceled(); err != nil {
+			return err
+		}
+		included, err := c.included(child)
+		if err != nil {
+			return err
+		}
+		if !included { // 被过滤的路径，目录不会向下遍历
+			continue
+		}
+		name := child.Name()
+		childDst := dst.Join(name)
+		if child.IsLink() {
+			if err := c.copySymlink(child, childDst); err != nil {
+				return err
+			}
+		} else if child.IsFile(false) {
+			if err := c.copyFile(child, childDst); err != nil {
+				return err
+			}
+		} else if child.IsDir(false) {
+			if err := c.copyDir(child, childDst); err != nil { // 递归复制子目录
+				return err
+			}
+		} else {
+			return common.WrapMsg(ErrCopyDir, "unsupported path type for copy: %q", child)
+		}
+	}
+	// 最后复制目录的元数据，避免创建子路径时修改时间，或者只读的权限导致无法创建子路径
+	return copyMetadata(src, dst, info, c.options)
+}
+
+// 复制文件到不存在的dst，出错或者取消时删除复制了一半的文件
+func (c *copier) copyFile(src, dst IPath) error {
+	if err := ensureMove(dst, false); err != nil {
+		return err
+	}
+	c.fileStart(src)
+	info, copied, err := c.copyFileContent(src, dst)
+	if err != nil {
+		_ = dst.Remove()
+		return err
+	}
+	// 关闭文件之后再复制元数据，避免写入的内容修改时间
+	if err := copyMetadata(src, dst, info, c.options); err != nil {
+		return err
+	}
+	c.fileDone(info.Size() - copied)
+	return nil
+}
+
+// 复制文件内容，返回源文件的信息，以及实际复制的字节数
+func (c *copier) copyFileContent(src, dst IPath) (fs.FileInfo, int64, error) {
+	inputFile, err := src.Open()
+	if err != nil {
+		return nil, 0, common.WrapSub(err, ErrCopyFile, "failed to open source file: %q", src)
+	}
+	defer closeFile(inputFile)
+
+	outputFile, err := dst.OpenWrite()
+	if err != nil {
+		return nil, 0, common.WrapSub(err, ErrCopyFile, "failed to open target file: %q", dst)
+	}
+	defer closeFile(outputFile)
+
+	info, err := inputFile.Stat()
+	if err != nil {
+		return nil, 0, common.WrapSub(err, ErrCopyFile, "failed to stat source file: %q", src)
+	}
+	var copied int64
+	err = copyFileData(outputFile, inputFile, info, func(n int64) error {
+		copied += n
+		c.progress.Bytes += n
+		c.report()
+		return c.canceled()
+	})
+	if err != nil {
+		return nil, 0, common.WrapSub(err, ErrCopyFile, "failed to copy file content from %q to %q", src, dst)
+	}
+	return info, copied, nil
+}
+
+// 复制符号链接本身，只会保留所有者
+func (c *copier) copySymlink(src, dst IPath) error {
+	if err := ensureMove(dst, false); err != nil {
+		return err
+	}
+	c.fileStart(src)
+	target, err := src.ReadLinkPath()
+	if err != nil {
+		return err
+	}
+	// 如果是相对路径，需要计算相对于链接的相对路径
+	if !target.IsAbs() {
+		target, err = target.RelToFile(dst) // 基于dst链接文件的相对路径
+		if err != nil {
+			return err
+		}
+	}
+	err = dst.Symlink(target, false)
+	if err != nil {
+		return err
+	}
+	if c.options.PreserveOwner {
+		info, err := src.Lstat()
+		if err != nil {
+			return err
+		}
+		if err := copyMetadata(src, dst, info, c.options); err != nil {
+			return err
+		}
+	}
+	c.fileDone(0)
+	return nil
+}
+
+// 按照选项把src的元数据复制到dst，info为src不跟随符号链接的信息。
+// 先修改所有者，因为修改所有者会清除setuid和setgid，最后修改时间
+func copyMetadata(src, dst IPath, info fs.FileInfo, options CopyOptions) error {
+	if options.PreserveOwner {
+		if uid, gid, ok := fileOwner(info); ok {
+			if err := os.Lchown(dst.String(), uid, gid); err != nil {
+				return common.WrapSub(err, ErrCopyMetadata, "failed to preserve owner of %q", dst)
+			}
+		}
+	}
+	if info.Mode()&fs.ModeSymlink != 0 { // 符号链接只保留所有者
+		return nil
+	}
+	if options.PreserveXattrs {
+		if err := copyXattrs(src, dst); err != nil {
+			return common.WrapSub(err, ErrCopyMetadata, "failed to preserve extended attributes from %q to %q", src, dst)
+		}
+	}
+	if options.PreserveMode {
+		mode := info.Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+		if err := os.Chmod(dst.String(), mode); err != nil {
+			return common.WrapSub(err, ErrCopyMetadata, "failed to preserve mode of %q", dst)
+		}
+	}
+	if options.PreserveTimes {
+		if err := os.Chtimes(dst.String(), time.Time{}, info.ModTime()); err != nil { // 零值表示不修改访问时间
+			return common.WrapSub(err, ErrCopyMetadata, "failed to preserve modification time of %q", dst)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+//go:build linux
+
+package path
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+const (
+	seekData = 3 // SEEK_DATA，从offset开始的下一个数据段
+	seekHole = 4 // SEEK_HOLE，从offset开始的下一个空洞
+)
+
+// 依次尝试reflink克隆、copy_file_range和普通的复制，稀疏文件只复制数据段，保留其中的空洞。
+// 每复制一块调用onChunk，onChunk返回错误时停止复制。dst必须是刚创建的空文件
+func copyFileData(dst, src *os.File, info fs.FileInfo, onChunk func(n int64) error) error {
+	if cloneFile(dst, src) == nil { // btrfs、xfs等文件系统上共享数据块，不需要复制
+		return nil
+	}
+	return copySparse(dst, src, info, onChunk)
+}
+
+// 无法克隆时（文件系统不支持返回EOPNOTSUPP，跨文件系统返回EXDEV）逐段复制，稀疏文件只复制数据段
+func copySparse(dst, src *os.File, info fs.FileInfo, onChunk func(n int64) error) error {
+	size := info.Size()
+	if !isSparse(info) {
+		return copyRange(dst, src, 0, size, onChunk)
+	}
+	for offset := int64(0); offset < size; {
+		data, err := src.Seek(offset, seekData)
+		if errors.Is(err, syscall.ENXIO) { // 后面只有空洞
+			break
+		}
+		if err != nil { // 文件系统不支持查找空洞，复制剩下的全部内容
+			return copyRange(dst, src, offset, size-offset, onChunk)
+		}
+		hole, err := src.Seek(data, seekHole)
+		if err != nil {
+			return err
+		}
+		if err := copyRange(dst, src, data, hole-data, onChunk); err != nil {
+			return err
+		}
+		offset = hole
+	}
+	return dst.Truncate(size) // 结尾的空洞只需要设置文件大小
+}
+
+// 使用FICLONE让dst和src共享数据块
+func cloneFile(dst, src *os.File) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dst.Fd(), ficloneRequest(), src.Fd())
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// FICLONE 的ioctl编号，即 _IOW(0x94, 9, int)，mips和ppc64上写方向的编码不同
+func ficloneRequest() uintptr {
+	switch runtime.GOARCH {
+	case "mips", "mipsle", "mips64", "mips64le", "ppc64", "ppc64le":
+		return 0x80049409
+	}
+	return 0x40049409
+}
+
+// 实际占用的块少于文件大小时，文件中有空洞
+func isSparse(info fs.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && stat.Blocks*512 < info.Size()
+}
+
+// 复制src中[offset, offset+length)的内容到dst的相同位置。(*os.File).ReadFrom 在Linux上
+// 先使用copy_file_range在内核中复制，不支持时（例如跨文件系统、内核版本太低）使用普通的复制
+func copyRange(dst, src *os.File, offset, length int64, onChunk func(n int64) error) error {
+	if _, err := src.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := dst.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	for length > 0 {
+		n, err := dst.ReadFrom(io.LimitReader(src, min(length, copyChunkSize)))
+		if err != nil {
+			return err
+		}
+		if n == 0 { // 源文件被截断
+			return nil
+		}
+		length -= n
+		if err := onChunk(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+//go:build linux
+
+package path
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"syscall"
+	"testing"
+)
+
+// 创建开头和中间有数据、其余部分都是空洞的文件，文件系统不支持空洞时跳过测试
+func createSparseFile(t *testing.T, path IPath, size int64) {
+	file, err := path.OpenWrite()
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	_, err1 := file.WriteAt([]byte("head"), 0)
+	_, err2 := file.WriteAt([]byte("middle"), size/2)
+	err3 := file.Truncate(size)
+	if err := errors.Join(err1, err2, err3, file.Close()); err != nil {
+		t.Fatalf("Failed to write sparse file: %v", err)
+	}
+	if blocks := blocksOf(t, path); blocks*512 >= size {
+		t.Skipf("File system does not support holes, %d blocks allocated", blocks)
+	}
+}
+
+// 文件实际占用的512字节块的数量
+func blocksOf(t *testing.T, path IPath) int64 {
+	info, err := path.Lstat()
+	if err != nil {
+		t.Fatalf("Failed to read file status: %v", err)
+	}
+	return info.Sys().(*syscall.Stat_t).Blocks
+}
+
+// 内容相同，并且dst占用的块不多于src，即空洞没有被填充
+func checkSparseCopy(t *testing.T, src, dst IPath) {
+	expected, err := os.ReadFile(src.String())
+	if err != nil {
+		t.Fatalf("Failed to read source file: %v", err)
+	}
+	result, err := os.ReadFile(dst.String())
+	if err != nil {
+		t.Fatalf("Failed to read copied file: %v", err)
+	}
+	if !slices.Equal(result, expected) {
+		t.Errorf("Copied file content differs from the source, size %d", len(result))
+	}
+	if srcBlocks, dstBlocks := blocksOf(t, src), blocksOf(t, dst); dstBlocks > srcBlocks {
+		t.Errorf("Expected holes to be kept, source has %d blocks, copy has %d", srcBlocks, dstBlocks)
+	}
+}
+
+func TestCopyFileData_Sparse(t *testing.T) {
+	const size = 8 << 20
+	root := New(t.TempDir())
+	src, dst := root.Join("sparse.img"), root.Join("sparse_copy.img")
+	createSparseFile(t, src, size)
+	if err := src.Copy(dst); err != nil {
+		t.Fatalf("Failed to copy sparse file: %v", err)
+	}
+	checkSparseCopy(t, src, dst)
+}
+
+// 克隆失败（EOPNOTSUPP、EXDEV）之后使用SEEK_DATA和copy_file_range逐段复制
+func TestCopyFileData_Fallback(t *testing.T) {
+	const size = 8 << 20
+	root := New(t.TempDir())
+	src, dst := root.Join("sparse.img"), root.Join("sparse_copy.img")
+	createSparseFile(t, src, size)
+
+	srcFile, err := os.Open(src.String())
+	if err != nil {
+		t.Fatalf("Failed to open source file: %v", err)
+	}
+	defer srcFile.Close()
+	dstFile, err := dst.OpenWrite()
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	info, err := srcFile.Stat()
+	if err != nil {
+		t.Fatalf("Failed to read file status: %v", err)
+	}
+	var copied int64
+	err = copySparse(dstFile, srcFile, info, func(n int64) error {
+		copied += n
+		return nil
+	})
+	if err := errors.Join(err, dstFile.Close()); err != nil {
+		t.Fatalf("Failed to copy sparse file: %v", err)
+	}
+	if copied >= size {
+		t.Errorf("Expected only data segments to be copied, copied %d of %d bytes", copied, size)
+	}
+	checkSparseCopy(t, src, dst)
+}
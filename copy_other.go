@@ -0,0 +1,27 @@
+//go:build !linux
+
+package path
+
+import (
+	"io"
+	"io/fs"
+	"os"
+)
+
+// 普通的复制，每复制一块调用onChunk，onChunk返回错误时停止复制。dst必须是刚创建的空文件
+func copyFileData(dst, src *os.File, info fs.FileInfo, onChunk func(n int64) error) error {
+	for {
+		n, err := io.CopyN(dst, src, copyChunkSize)
+		if n > 0 {
+			if err := onChunk(n); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
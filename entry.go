@@ -0,0 +1,100 @@
+package path
+
+import (
+	"io/fs"
+	"os"
+
+	"github.com/viocha/go-pathlib/internal/common"
+)
+
+// Entry 读取目录得到的子路径，缓存了目录项的类型和不跟随符号链接的文件信息，
+// 判断类型时不需要再次访问文件系统，只有跟随符号链接的查询才会访问。
+// 缓存的是读取目录时的状态，之后文件发生的变化不会反映出来
+type Entry struct {
+	IPath
+	dirEntry fs.DirEntry // 读取目录得到的目录项，通过文件信息创建时为nil
+	info     fs.FileInfo // 缓存的文件信息，第一次调用Info时才会读取
+}
+
+func newDirEntry(dir IPath, dirEntry fs.DirEntry) *Entry {
+	return &Entry{IPath: dir.Join(dirEntry.Name()), dirEntry: dirEntry}
+}
+
+func newInfoEntry(path IPath, info fs.FileInfo) *Entry {
+	return &Entry{IPath: path, info: info}
+}
+
+// 返回缓存了不跟随符号链接的文件信息的路径，path已经是 *Entry 时直接返回，读取失败时返回path本身
+func lstatEntry(path IPath) IPath {
+	if _, ok := path.(*Entry); ok {
+		return path
+	}
+	info, err := path.Lstat()
+	if err != nil {
+		return path
+	}
+	return newInfoEntry(path, info)
+}
+
+// 读取目录下的所有子路径，并缓存目录项的信息
+func ReadDirEntries(root IPath) ([]*Entry, error) {
+	dirEntries, err := os.ReadDir(root.String())
+	if err != nil {
+		return nil, common.WrapSub(err, ErrReadDir, "failed to read directory: %q", root)
+	}
+	entries := make([]*Entry, len(dirEntries))
+	for i, dirEntry := range dirEntries {
+		entries[i] = newDirEntry(root, dirEntry)
+	}
+	return entries, nil
+}
+
+// 目录项的类型，等同于 Lstat().Mode().Type()
+func (e *Entry) Type() fs.FileMode {
+	if e.info != nil {
+		return e.info.Mode().Type()
+	}
+	return e.dirEntry.Type()
+}
+
+// 不跟随符号链接的文件信息，只会读取一次，某些系统上读取目录时已经得到
+func (e *Entry) Info() (fs.FileInfo, error) {
+	if e.info == nil {
+		info, err := e.dirEntry.Info()
+		if err != nil {
+			return nil, common.WrapSub(err, ErrReadLstat, "failed to read file status without following symlink: %q", e)
+		}
+		e.info = info
+	}
+	return e.info, nil
+}
+
+func (e *Entry) Lstat() (os.FileInfo, error) {
+	return e.Info()
+}
+
+func (e *Entry) IsLink() bool {
+	return e.Type()&fs.ModeSymlink != 0
+}
+
+// 不是符号链接或者不跟随符号链接时，直接使用缓存的类型
+func (e *Entry) Exists(follow ...bool) bool {
+	if e.IsLink() && common.ParseOptional(follow, true) {
+		return e.IPath.Exists(true)
+	}
+	return true
+}
+
+func (e *Entry) IsFile(follow ...bool) bool {
+	if e.IsLink() && common.ParseOptional(follow, true) {
+		return e.IPath.IsFile(true)
+	}
+	return e.Type().IsRegular()
+}
+
+func (e *Entry) IsDir(follow ...bool) bool {
+	if e.IsLink() && common.ParseOptional(follow, true) {
+		return e.IPath.IsDir(true)
+	}
+	return e.Type().IsDir()
+}
@@ -0,0 +1,12 @@
+//go:build !unix && !windows
+
+package path
+
+// 无法获取文件标识，使用绝对路径代替
+func fileKeyOf(dir IPath) (fileKey, error) {
+	abs, err := dir.ToAbs()
+	if err != nil {
+		return fileKey{}, err
+	}
+	return fileKey{path: abs.String()}, nil
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package path
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// 目录的文件标识，dir不能是符号链接。dir是 *Entry 时使用缓存的文件信息，没有缓存时才会读取
+func fileKeyOf(dir IPath) (fileKey, error) {
+	var info fs.FileInfo
+	var err error
+	if entry, ok := dir.(*Entry); ok {
+		info, err = entry.Info()
+	} else {
+		info, err = dir.Lstat()
+	}
+	if err != nil {
+		return fileKey{}, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileKey{path: dir.String()}, nil
+	}
+	return fileKey{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, nil
+}
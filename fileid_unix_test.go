@@ -0,0 +1,25 @@
+//go:build unix
+
+package path
+
+import "testing"
+
+func TestFileKeyOf(t *testing.T) {
+	dir := New(t.TempDir())
+	info, err := dir.Lstat()
+	if err != nil {
+		t.Fatalf("Failed to read file status: %v", err)
+	}
+	expected, err := fileKeyOf(dir)
+	if err != nil {
+		t.Fatalf("Failed to read file key: %v", err)
+	}
+	// 缓存了文件信息的 *Entry 不会再次读取，即使路径已经不存在
+	entry := newInfoEntry(dir.Join("missing"), info)
+	if key, err := fileKeyOf(entry); err != nil || key != expected {
+		t.Errorf("Expected the cached key %v, got %v (%v)", expected, key, err)
+	}
+	if _, err := fileKeyOf(dir.Join("missing")); err == nil {
+		t.Errorf("Expected an error for a missing path without cached info")
+	}
+}
@@ -0,0 +1,34 @@
+//go:build windows
+
+package path
+
+import (
+	"syscall"
+
+	"github.com/viocha/go-pathlib/internal/common"
+)
+
+// 目录的文件标识，和 os.SameFile 使用的信息相同
+func fileKeyOf(dir IPath) (fileKey, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(dir.String())
+	if err != nil {
+		return fileKey{}, common.WrapSub(err, ErrReadStat, "failed to read file status: %q", dir)
+	}
+	// 打开目录需要 FILE_FLAG_BACKUP_SEMANTICS
+	handle, err := syscall.CreateFile(pathPtr, 0,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_EXISTING, syscall.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return fileKey{}, common.WrapSub(err, ErrReadStat, "failed to read file status: %q", dir)
+	}
+	defer syscall.CloseHandle(handle)
+
+	var data syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(handle, &data); err != nil {
+		return fileKey{}, common.WrapSub(err, ErrReadStat, "failed to read file status: %q", dir)
+	}
+	return fileKey{
+		dev: uint64(data.VolumeSerialNumber),
+		ino: uint64(data.FileIndexHigh)<<32 | uint64(data.FileIndexLow),
+	}, nil
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/viocha/go-pathlib/internal/common"
+	"github.com/viocha/go-pathlib/purepath"
 )
 
 var (
@@ -13,58 +15,172 @@ var (
 	ErrWalkCycle    = errors.New("walk cycle detected")
 	ErrCopyDir      = errors.New("copy directory error")
 	ErrCopyFile     = errors.New("copy file error")
+	ErrCopyMetadata = errors.New("copy metadata error")
+	ErrGlobPattern  = errors.New("unsupported glob pattern")
+	ErrGlobOptions  = errors.New("invalid glob options")
+)
+
+// 所有的过滤都在遍历时进行，被排除的目录不会向下遍历
+type GlobOptions struct {
+	Follow        bool           // 是否跟随符号链接，默认不跟随
+	SkipOnCycle   bool           // 是否跳过循环链接，默认不跳过
+	Ignore        *IgnoreMatcher // 忽略规则，被忽略的目录不会向下遍历，默认不忽略
+	Relative      bool           // 是否返回相对于root的路径，默认返回和root拼接后的路径
+	OnlyFiles     bool           // 只返回文件，是否跟随符号链接判断由Follow决定
+	OnlyDirs      bool           // 只返回目录，不能和OnlyFiles同时使用
+	MaxDepth      int            // 最大深度，root的子路径深度为1，小于等于0表示不限制
+	Exclude       []string       // 排除的模式，从右侧匹配相对于root的路径，例如 node_modules 会排除任意层级的 node_modules
+	IncludeHidden bool           // 是否包含通配符匹配到的隐藏文件和目录，默认跳过，模式中直接写出的隐藏名称总是会包含
+	CaseSensitive *bool          // 模式和Exclude是否区分大小写，nil表示使用路径风格的默认规则，对已经编译的模式无效
+	Sort          bool           // 是否按照名称顺序遍历，得到确定的、排序后的结果
+}
+
+type WalkOptions struct {
+	Follow   bool                 // 是否跟随符号链接，默认不跟随
+	Ignore   *IgnoreMatcher       // 忽略规则，被忽略的路径不会传给fn，被忽略的目录不会向下遍历，默认不忽略
+	MinDepth int                  // 最小深度，root的深度为0，更浅的路径不会传给fn，但是仍然会向下遍历
+	MaxDepth int                  // 最大深度，root的子路径深度为1，小于等于0表示不限制
+	Sort     bool                 // 是否按照名称顺序遍历子路径，得到确定的遍历顺序
+	SortFunc func(a, b IPath) int // 自定义子路径的遍历顺序，不为nil时忽略Sort
+	// 返回true时不进入这个目录，在目录传给fn或者被迭代器产生之后、读取子路径之前调用，
+	// 所以可以根据循环体中记录的状态决定是否跳过
+	Prune func(dir IPath) bool
+	// 遍历出错时的处理方式，例如读取目录失败、链接的目标不存在或者检测到循环，为nil时把错误传给fn
+	OnError func(path IPath, err error) WalkErrorAction
+
+	Workers int // WalkParallel 同时读取目录的goroutine数量，小于等于0时使用CPU的数量
+}
+
+// 遍历出错时的处理方式
+type WalkErrorAction int
+
+const (
+	WalkErrorReport   WalkErrorAction = iota // 把错误传给fn，由fn的返回值决定是否继续
+	WalkErrorContinue                        // 忽略错误并继续，无法继续访问的路径（例如读取目录失败）和 WalkErrorSkip 相同
+	WalkErrorSkip                            // 忽略错误，并跳过出错的路径，目录不会向下遍历
+	WalkErrorAbort                           // 终止遍历，返回这个错误
 )
 
 func shouldStopWalk(err error) bool {
 	return !errors.Is(err, nil) && !errors.Is(err, WalkSkip)
 }
 
-func Walk(root IPath, fn func(path IPath, err error) error, follow ...bool) error {
-	isFollow := common.ParseOptional(follow, false) // 默认不跟随符号链接
-
-	visited := make(map[string]int) // 用于检测循环，三色标记法：1表示正在访问，2表示访问结束
-	return walkRecursively(root, fn, isFollow, visited)
+// 根据onError处理遍历时的错误，返回是否继续处理出错的路径，以及需要返回的错误
+func handleWalkError(onError func(path IPath, err error) WalkErrorAction,
+	fn func(path IPath, err error) error, path IPath, err error) (bool, error) {
+	action := WalkErrorReport
+	if onError != nil {
+		action = onError(path, err)
+	}
+	switch action {
+	case WalkErrorContinue:
+		return true, nil
+	case WalkErrorSkip:
+		return false, nil
+	case WalkErrorAbort:
+		return false, err
+	default: // fn返回nil时继续处理出错的路径，例如忽略文件加载失败时不使用它继续遍历目录
+		err = fn(path, err)
+		return err == nil, err
+	}
 }
 
-func walkRecursively(root IPath, fn func(path IPath, err error) error, follow bool, visited map[string]int) error {
-	// 转换成绝对路径
-	rootAbs, err := root.ToAbs()
-	if err != nil {
-		return fn(root, err)
+// 子路径的遍历顺序，不需要排序时返回nil
+func walkCompareFunc(options WalkOptions) func(a, b IPath) int {
+	if options.SortFunc != nil {
+		return options.SortFunc
 	}
-	pathKey := rootAbs.String()
-	if visited[pathKey] == 1 { // 重复访问，检测到循环
-		return fn(rootAbs, common.WrapMsg(ErrWalkCycle, "cycle detected at %q", rootAbs))
+	if options.Sort {
+		return compareByName
 	}
-	if visited[pathKey] == 2 { // 已经访问结束
-		return nil // 已经访问过，直接返回
+	return nil
+}
+
+// 按照名称比较同一个目录下的路径
+func compareByName(a, b IPath) int {
+	return a.Compare(b)
+}
+
+func Walk(root IPath, fn func(path IPath, err error) error, walkOptions ...WalkOptions) error {
+	options := common.ParseOptional(walkOptions, WalkOptions{}) // 默认不跟随符号链接，不忽略任何路径
+
+	w := &walker{
+		fn:       fn,
+		options:  options,
+		visited:  make(map[fileKey]IPath),
+		minDepth: options.MinDepth,
+		maxDepth: options.MaxDepth,
+		compare:  walkCompareFunc(options),
+	}
+	return w.walkRecursively(root, nil, options.Ignore.rootFrames(), nil)
+}
+
+type walker struct {
+	fn       func(path IPath, err error) error
+	options  WalkOptions
+	visited  map[fileKey]IPath    // 按照文件标识记录已经访问过的目录，用于检测循环和重复访问
+	chain    dirChain             // 当前正在访问的目录链
+	pattern  *purepath.Pattern    // 只把匹配模式的路径传给fn，并且只进入可能匹配的目录，为nil表示不使用模式
+	relative bool                 // 传给fn的路径是否相对于遍历的根目录，出错时仍然传入实际的路径
+	minDepth int                  // 更浅的路径不会传给fn
+	maxDepth int                  // 大于0时不会进入更深的路径
+	compare  func(a, b IPath) int // 子路径的遍历顺序，为nil表示不排序
+
+	// 返回true时跳过这个子路径，目录也不会向下遍历，state为父路径匹配模式的状态
+	prune  func(child IPath, rel []string, state *purepath.PatternState) bool
+	accept func(path IPath) bool // 返回false时不把路径传给fn，但是仍然会向下遍历
+}
+
+// rel为root相对于遍历根目录的各个部分，frames为当前生效的忽略规则，state为root匹配模式的状态
+func (w *walker) walkRecursively(root IPath, rel []string, frames []ignoreFrame, state *purepath.PatternState) error {
+	fn := w.fn
+	var err error
+
+	// 尝试解析符号链接，解析后的路径只用于读取文件信息和检测循环，
+	// 报告的路径和子路径仍然位于链接下面，保持相对于遍历的根目录
+	target := root
+	if w.options.Follow && root.IsLink() { // 如果跟随符号链接
+		var err error
+		if target, err = root.Resolve(); err != nil { // 可能目标路径不存在，或者无法转换成绝对路径
+			_, err = w.handleError(root, err)
+			return err
+		}
 	}
-	visited[pathKey] = 1 // 标记为正在访问
-	defer func() {
-		visited[pathKey] = 2 // 函数返回时，标记为访问结束
-	}()
 
-	// 尝试解析符号链接
-	if follow && root.IsLink() { // 如果跟随符号链接
-		target, err := root.Resolve()
-		if err != nil { // 可能目标路径不存在，或者无法转换成绝对路径
-			return fn(root, err)
+	// 按照文件标识检测循环，同一个目录只会访问一次。
+	// 不是读取目录得到的路径（根路径和链接的目标）只读取一次文件信息，判断类型和获取文件标识时共用
+	stat := lstatEntry(target)
+	isDir := stat.IsDir(false)
+	if isDir {
+		key, err := fileKeyOf(stat)
+		if err == nil {
+			err = visitDir(w.visited, w.chain, key, root)
 		}
-		if rootAbs.IsRelTo(target, false) { // 回到了上级目录
-			return fn(rootAbs, common.WrapMsg(ErrWalkCycle, "symbolic link %q points to a parent directory %q",
-				rootAbs, target))
+		if err != nil { // 无法检测循环的目录，以及循环的目录，都不会继续访问
+			_, err = w.handleError(root, err)
+			return err
 		}
-		root = target // 使用解析后的路径继续遍历
+		parentChain := w.chain
+		w.chain = w.chain.push(key, root)
+		defer func() {
+			w.chain = parentChain // 函数返回时，离开这个目录
+		}()
 	}
 
-	// 先处理根路径
-	err = fn(root, nil)
-	if shouldStopWalk(err) {
-		return err
+	// 先处理根路径，使用模式时只处理匹配的路径
+	if len(rel) >= w.minDepth && (state == nil || state.Matched()) && (w.accept == nil || w.accept(root)) {
+		path := root
+		if w.relative {
+			path = FromPurePath(purepath.NewWithFlavour(root.Flavour(), rel...))
+		}
+		err = fn(path, nil)
+		if shouldStopWalk(err) {
+			return err
+		}
 	}
 
 	// 不是目录，则已经访问结束
-	if !root.IsDir(false) {
+	if !isDir {
 		return nil
 	}
 
@@ -72,142 +188,199 @@ func walkRecursively(root IPath, fn func(path IPath, err error) error, follow bo
 	if errors.Is(err, WalkSkip) { // 跳过当前目录
 		return nil
 	}
-	children, err := root.ReadDir()
-	if err != nil {
-		return fn(root, err) // 读取目录失败，直接返回错误
+	if w.options.Prune != nil && w.options.Prune(root) {
+		return nil
 	}
-	for _, child := range children {
-		err := walkRecursively(child, fn, follow, visited) // 递归遍历子路径
-		if shouldStopWalk(err) {
+	if state != nil && !state.CanDescend() { // 更深的路径不可能匹配模式
+		return nil
+	}
+	if w.maxDepth > 0 && len(rel) >= w.maxDepth { // 已经到达最大深度
+		return nil
+	}
+	children, err := w.readDir(root, state)
+	if err != nil { // 读取目录失败，无法继续向下遍历
+		_, err = w.handleError(root, err)
+		return err
+	}
+	if w.compare != nil {
+		slices.SortFunc(children, w.compare)
+	}
+	// 加载当前目录下的忽略文件，忽略出错时不使用这个目录的规则
+	frame, ok, err := w.options.Ignore.loadFrame(root, len(rel))
+	if err != nil {
+		if next, err := w.handleError(root, err); !next {
 			return err
 		}
 	}
-
-	return nil
-}
-
-// src和dst必须是一个目录
-func CopyMerge(src, dst IPath, mode MergeMode) error {
-	children, err := src.ReadDir()
-	if err != nil {
-		return err
+	if ok {
+		frames = pushIgnoreFrame(frames, frame)
 	}
-
 	for _, child := range children {
-		name := child.Name()
-		childDst := dst.Join(name) // 将child 复制到 childDst
-		// 目标路径存在，尝试进行解决冲突
-		if childDst.Exists(false) {
-			if childDst.IsDir(false) { // 目标是目录
-				// 不允许使用文件覆盖目录
-				if !child.IsDir(false) {
-					return common.WrapMsg(ErrTargetExists, "target path %q is a directory but source path %q is not a directory",
-						childDst, child)
-				}
-				// 如果源路径是目录，递归合并
-				if err := CopyMerge(child, childDst, mode); err != nil {
-					return err
-				}
-			}
-			switch mode {
-			case MergeModeError: // 返回错误
-				return common.WrapMsg(ErrTargetExists, "target path %q already exists, cannot merge", childDst)
-			case MergeModeSkip: // 跳过冲突
-				continue
-			case MergeModeReplace: // 替换冲突
-				if err := childDst.Remove(); err != nil { // 删除目标文件或链接
-					return err
-				}
+		childRel := append(slices.Clip(rel), child.Name())
+		if len(frames) > 0 && ignoredBy(frames, childRel, child.IsDir(w.options.Follow)) {
+			continue // 被忽略的路径，目录也不会向下遍历
+		}
+		if w.prune != nil && w.prune(child, childRel, state) {
+			continue
+		}
+		var childState *purepath.PatternState
+		if state != nil {
+			childState = state.Next(child.Name())
+			if !childState.Matched() && !childState.CanDescend() {
+				continue // 自身和下面的路径都不可能匹配模式
 			}
 		}
-		// 目标路径不存在，使用 Copy 方法进行复制
-		if err := child.Copy(childDst); err != nil {
+		err := w.walkRecursively(child, childRel, frames, childState) // 递归遍历子路径
+		if shouldStopWalk(err) {
 			return err
 		}
 	}
+
 	return nil
 }
 
-// 递归复制整个文件夹，目标文件夹必须不存在，且会自动创建父目录
-func CopyDir(src, dst IPath) error {
-	// 确保目标路径的父目录存在，以及目标路径不存在
-	if err := ensureMove(dst, false); err != nil {
-		return err
-	}
-	// 读取源目录的子目录和文件
-	children, err := src.ReadDir()
-	if err != nil {
-		return err
+func (w *walker) handleError(path IPath, err error) (bool, error) {
+	return handleWalkError(w.options.OnError, w.fn, path, err)
+}
+
+// 读取目录的子路径，如果模式的下一部分只能是字面量，直接检查这些名称是否存在，不读取整个目录
+func (w *walker) readDir(dir IPath, state *purepath.PatternState) ([]IPath, error) {
+	if state == nil || !w.pattern.CaseSensitive() {
+		return dir.ReadDir() // 不区分大小写的模式需要使用磁盘上的名称，不能直接使用字面量
 	}
-	// 先创建目标目录，再拷贝子目录和文件
-	if err := dst.Mkdir(); err != nil {
-		return err
+	names, ok := state.Literals()
+	if !ok {
+		return dir.ReadDir()
 	}
-	for _, child := range children {
-		name := child.Name()
-		childDst := dst.Join(name)
-		if child.IsLink() {
-			if err := CopySymlink(child, childDst); err != nil {
-				return err
-			}
-		} else if child.IsFile(false) {
-			if err := CopyFile(child, childDst); err != nil {
-				return err
-			}
-		} else if child.IsDir(false) {
-			if err := CopyDir(child, childDst); err != nil { // 递归复制子目录
-				return err
-			}
-		} else {
-			return common.WrapMsg(ErrCopyDir, "unsupported path type for copy: %q", child)
+	var children []IPath
+	for _, name := range names {
+		child := dir.Join(name)
+		if info, err := child.Lstat(); err == nil {
+			children = append(children, newInfoEntry(child, info))
 		}
 	}
-	return nil
+	return children, nil
 }
 
-// 目标路径必须不存在，且会自动创建父目录
-func CopyFile(src, dst IPath) error {
-	if err := ensureMove(dst, false); err != nil {
-		return err
+// 使用通配符读取root下所有匹配的路径，模式相对于root，不支持带有anchor的模式，支持**，模式只会编译一次
+func Glob(root IPath, pattern string, globOptions ...GlobOptions) ([]IPath, error) {
+	options := common.ParseOptional(globOptions, GlobOptions{})
+	compiled, err := purepath.CompilePatternWithFlavour(root.Flavour(), pattern,
+		purepath.PatternOptions{CaseSensitive: options.CaseSensitive})
+	if err != nil {
+		return nil, err
 	}
+	return GlobPattern(root, compiled, globOptions...)
+}
+
+// 递归读取root下所有匹配的路径，等同于使用 **/pattern 调用 Glob
+func RGlob(root IPath, pattern string, globOptions ...GlobOptions) ([]IPath, error) {
+	return Glob(root, "**/"+pattern, globOptions...)
+}
 
-	inputFile, err := src.Open()
+// 使用编译后的模式读取root下所有匹配的路径，模式相对于root逐个部分匹配，只会读取可能匹配的目录，
+// 区分大小写并且下一部分是字面量时直接检查名称是否存在，只有 ** 会无限向下遍历
+func GlobPattern(root IPath, pattern *purepath.Pattern, globOptions ...GlobOptions) ([]IPath, error) {
+	options := common.ParseOptional(globOptions, GlobOptions{}) // 默认不跟随符号链接，不跳过循环链接
+	var result []IPath
+	w, err := newGlobWalker(root, pattern, options, func(path IPath, err error) error {
+		if err != nil {
+			if errors.Is(err, ErrWalkCycle) && options.SkipOnCycle { // 检测到循环链接，并允许跳过
+				return nil
+			}
+			return err
+		}
+		result = append(result, path) // 只有匹配模式并且满足选项的路径会传进来
+		return nil
+	})
 	if err != nil {
-		return common.WrapSub(err, ErrCopyFile, "failed to open source file: %q", src)
+		return nil, err
 	}
-	defer closeFile(inputFile)
+	// 从根路径开始逐个部分匹配模式，只读取可能匹配的目录
+	err = w.walkRecursively(root, nil, options.Ignore.rootFrames(), pattern.Start())
 
-	outputFile, err := dst.OpenWrite()
 	if err != nil {
-		return common.WrapSub(err, ErrCopyFile, "failed to open target file: %q", dst)
+		return nil, err
+	}
+	return result, nil
+}
+
+// 根据Glob的选项创建walker，fn只会接收到匹配模式并且满足选项的路径，以及遍历时的错误
+func newGlobWalker(root IPath, pattern *purepath.Pattern, options GlobOptions,
+	fn func(path IPath, err error) error) (*walker, error) {
+	if pattern.IsAnchored() {
+		return nil, common.WrapMsg(ErrGlobPattern, "pattern %q must be relative to %q", pattern, root)
+	}
+	if options.OnlyFiles && options.OnlyDirs {
+		return nil, common.WrapMsg(ErrGlobOptions, "OnlyFiles and OnlyDirs cannot be used together")
+	}
+	excludes := make([]*purepath.Pattern, len(options.Exclude))
+	for i, exclude := range options.Exclude {
+		compiled, err := purepath.CompilePatternWithFlavour(root.Flavour(), exclude,
+			purepath.PatternOptions{CaseSensitive: options.CaseSensitive})
+		if err != nil {
+			return nil, err
+		}
+		if compiled.IsAnchored() {
+			return nil, common.WrapMsg(ErrGlobPattern, "exclude pattern %q must be relative to %q", exclude, root)
+		}
+		excludes[i] = compiled
 	}
-	defer closeFile(outputFile)
 
-	if _, err = inputFile.WriteTo(outputFile); err != nil {
-		return common.WrapSub(err, ErrCopyFile, "failed to copy file content from %q to %q", src, dst)
+	w := &walker{
+		fn:       fn,
+		options:  WalkOptions{Follow: options.Follow, Ignore: options.Ignore},
+		visited:  make(map[fileKey]IPath),
+		pattern:  pattern,
+		relative: options.Relative,
+		maxDepth: options.MaxDepth,
 	}
-	return nil
+	if options.Sort {
+		w.compare = compareByName
+	}
+	if len(excludes) > 0 || !options.IncludeHidden {
+		w.prune = func(child IPath, rel []string, state *purepath.PatternState) bool {
+			if !options.IncludeHidden && isHidden(child) && !state.MatchesLiteral(child.Name()) {
+				return true // 只跳过通配符匹配到的隐藏路径
+			}
+			if len(excludes) == 0 {
+				return false
+			}
+			relPath := purepath.NewWithFlavour(root.Flavour(), rel...) // 只有需要匹配排除的模式时才创建
+			return slices.ContainsFunc(excludes, relPath.MatchPattern)
+		}
+	}
+	if options.OnlyFiles || options.OnlyDirs {
+		w.accept = func(path IPath) bool {
+			if options.OnlyFiles {
+				return path.IsFile(options.Follow)
+			}
+			return path.IsDir(options.Follow)
+		}
+	}
+	return w, nil
 }
 
-// 目标路径必须不存在，且会自动创建父目录
-func CopySymlink(src, dst IPath) error {
-	if err := ensureMove(dst, false); err != nil {
-		return err
+// 移动文件或目录到新路径，不跟随符号链接，移动的是符号链接本身
+func Move(src, dst IPath, replace ...bool) error {
+	if src.SameFile(dst) { // 如果源路径和目标路径相同，直接返回
+		return nil
 	}
-	target, err := src.ReadLinkPath()
-	if err != nil {
+	// 确保目标路径的父目录存在，以及目标路径不存在
+	if err := ensureMove(dst, replace...); err != nil {
 		return err
 	}
-	// 如果是相对路径，需要计算相对于链接的相对路径
-	if !target.IsAbs() {
-		target, err = target.RelToFile(dst) // 基于dst链接文件的相对路径
-		if err != nil {
+	if src.IsLink() { // 如果是符号链接，直接使用 os.Symlink 创建新的符号链接并删除旧的符号链接
+		if err := CopySymlink(src, dst); err != nil {
 			return err
 		}
-	}
-	err = dst.Symlink(target, false)
-	if err != nil {
-		return err
+		if err := src.Remove(); err != nil {
+			return err
+		}
+	} else if src.IsFile(false) || src.IsDir(false) { // 如果是文件或目录，直接使用 os.Rename 移动文件
+		if err := os.Rename(src.String(), dst.String()); err != nil {
+			return common.WrapSub(err, ErrMove, "failed to move path from %q to %q", src, dst)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package path
+
+import "strings"
+
+// 以 . 开头的名称是隐藏的
+func isHidden(path IPath) bool {
+	return strings.HasPrefix(path.Name(), ".")
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package path
+
+import (
+	"strings"
+	"syscall"
+)
+
+// 以 . 开头的名称，或者带有隐藏属性的文件是隐藏的
+func isHidden(path IPath) bool {
+	if strings.HasPrefix(path.Name(), ".") {
+		return true
+	}
+	if entry, ok := path.(*Entry); ok { // 读取目录时已经得到了文件属性
+		if info, err := entry.Info(); err == nil {
+			if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+				return data.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+			}
+		}
+	}
+	pathPtr, err := syscall.UTF16PtrFromString(path.String())
+	if err != nil {
+		return false
+	}
+	attrs, err := syscall.GetFileAttributes(pathPtr)
+	if err != nil {
+		return false
+	}
+	return attrs&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+}
@@ -0,0 +1,235 @@
+package path
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/viocha/go-pathlib/internal/common"
+	"github.com/viocha/go-pathlib/purepath"
+)
+
+const GitIgnoreFile = ".gitignore" // git使用的忽略文件名
+
+var (
+	ErrIgnoreRule = errors.New("invalid ignore rule")
+)
+
+// 使用 .gitignore 语法判断路径是否被忽略，规则总是大小写敏感，并使用 / 分隔路径
+type IgnoreMatcher struct {
+	fileName string       // 每个目录下加载的忽略文件名，为空表示不加载
+	rules    []ignoreRule // 相对于遍历根目录的规则
+}
+
+type ignoreRule struct {
+	pattern *purepath.Pattern
+	negate  bool // 以 ! 开头，重新包含之前被忽略的路径
+	dirOnly bool // 以 / 结尾，只匹配目录
+}
+
+// 一个目录中的规则，base为该目录相对于遍历根目录的层数
+type ignoreFrame struct {
+	base  int
+	rules []ignoreRule
+}
+
+// 创建忽略规则匹配器，fileName为每个目录下要加载的忽略文件名，例如 GitIgnoreFile，为空则不加载；
+// lines为额外的规则，相对于遍历的根目录，优先级低于忽略文件中的规则
+func NewIgnoreMatcher(fileName string, lines ...string) (*IgnoreMatcher, error) {
+	rules, err := parseIgnoreRules(lines)
+	if err != nil {
+		return nil, err
+	}
+	return &IgnoreMatcher{fileName: fileName, rules: rules}, nil
+}
+
+func MustNewIgnoreMatcher(fileName string, lines ...string) *IgnoreMatcher {
+	matcher, err := NewIgnoreMatcher(fileName, lines...)
+	if err != nil {
+		panic(err)
+	}
+	return matcher
+}
+
+// 从文件中添加规则，规则相对于遍历的根目录，例如 .git/info/exclude
+func (m *IgnoreMatcher) AddFile(file IPath) error {
+	text, err := file.Read()
+	if err != nil {
+		return err
+	}
+	rules, err := parseIgnoreRules(strings.Split(text, "\n"))
+	if err != nil {
+		return common.WrapMsg(err, "failed to parse ignore file %q", file)
+	}
+	m.rules = append(m.rules, rules...)
+	return nil
+}
+
+func (m *IgnoreMatcher) MustAddFile(file IPath) {
+	if err := m.AddFile(file); err != nil {
+		panic(err)
+	}
+}
+
+// 判断root下的path是否被忽略，会加载root到path之间每个目录的忽略文件，
+// 被忽略的目录下的所有路径也被认为是忽略的。不跟随符号链接判断path是否是目录
+func (m *IgnoreMatcher) Ignored(root, path IPath) (bool, error) {
+	rel, err := path.RelTo(root)
+	if err != nil {
+		return false, err
+	}
+	parts := rel.Parts()
+	frames := m.rootFrames()
+	dir := root
+	for i, name := range parts {
+		frame, ok, err := m.loadFrame(dir, i)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			frames = pushIgnoreFrame(frames, frame)
+		}
+		isDir := i < len(parts)-1 || path.IsDir(false)
+		if ignoredBy(frames, parts[:i+1], isDir) {
+			return true, nil
+		}
+		dir = dir.Join(name)
+	}
+	return false, nil
+}
+
+func (m *IgnoreMatcher) MustIgnored(root, path IPath) bool {
+	ignored, err := m.Ignored(root, path)
+	if err != nil {
+		panic(err)
+	}
+	return ignored
+}
+
+// 根目录的规则，m为nil时没有任何规则
+func (m *IgnoreMatcher) rootFrames() []ignoreFrame {
+	if m == nil || len(m.rules) == 0 {
+		return nil
+	}
+	return []ignoreFrame{{base: 0, rules: m.rules}}
+}
+
+// 加载dir下的忽略文件，depth为dir相对于根目录的层数，文件不存在时返回false
+func (m *IgnoreMatcher) loadFrame(dir IPath, depth int) (ignoreFrame, bool, error) {
+	if m == nil || m.fileName == "" {
+		return ignoreFrame{}, false, nil
+	}
+	file := dir.Join(m.fileName)
+	if !file.IsFile() {
+		return ignoreFrame{}, false, nil
+	}
+	text, err := file.Read()
+	if err != nil {
+		return ignoreFrame{}, false, err
+	}
+	rules, err := parseIgnoreRules(strings.Split(text, "\n"))
+	if err != nil {
+		return ignoreFrame{}, false, common.WrapMsg(err, "failed to parse ignore file %q", file)
+	}
+	return ignoreFrame{base: depth, rules: rules}, len(rules) > 0, nil
+}
+
+// 判断相对于根目录的路径是否被忽略，frames按照从外到内排列，最后一个匹配的规则生效
+func ignoredBy(frames []ignoreFrame, rel []string, isDir bool) bool {
+	ignored := false
+	for _, frame := range frames {
+		path := purepath.NewPosix(rel[frame.base:]...)
+		for _, rule := range frame.rules {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if rule.pattern.FullMatch(path) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+func parseIgnoreRules(lines []string) ([]ignoreRule, error) {
+	var rules []ignoreRule
+	for i, line := range lines {
+		rule, ok, err := parseIgnoreRule(line)
+		if err != nil {
+			return nil, common.WrapMsg(err, "line %d: %q", i+1, line)
+		}
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// 解析一行规则，空行和注释返回false
+func parseIgnoreRule(line string) (ignoreRule, bool, error) {
+	line = strings.TrimSuffix(line, "\r")
+	// 去掉没有转义的行尾空格
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false, nil
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// 开头或中间包含 / 的规则相对于忽略文件所在目录，否则匹配任意层级
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return ignoreRule{}, false, nil
+	}
+	if !anchored {
+		line = "**/" + line
+	}
+	// 结尾的 /** 只匹配目录里面的内容，不匹配目录本身
+	if strings.HasSuffix(line, "/**") {
+		line += "/*"
+	}
+
+	pattern, err := purepath.CompilePatternWithFlavour(purepath.FlavourPosix, escapeBraces(line))
+	if err != nil {
+		return ignoreRule{}, false, common.WrapSub(err, ErrIgnoreRule, "failed to compile rule %q", line)
+	}
+	rule.pattern = pattern
+	return rule, true, nil
+}
+
+// .gitignore 不支持花括号展开，转义所有没有转义的花括号
+func escapeBraces(pattern string) string {
+	var builder strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			builder.WriteByte(c)
+			i++
+			builder.WriteByte(pattern[i])
+		case c == '{' || c == '}':
+			builder.WriteByte('\\')
+			builder.WriteByte(c)
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String()
+}
+
+// 在frames后面追加一个frame，不会修改原来的底层数组
+func pushIgnoreFrame(frames []ignoreFrame, frame ignoreFrame) []ignoreFrame {
+	return append(slices.Clip(frames), frame)
+}
@@ -0,0 +1,166 @@
+package path
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 创建用于测试忽略规则的文件树，返回根目录
+func createIgnoreFileTree(t *testing.T) IPath {
+	root := New(t.TempDir())
+	files := map[string]string{
+		".gitignore":       "# 注释\n*.log\n!keep.log\nbuild/\n/top.txt\ndocs/**\n",
+		"a.md":             "",
+		"a.log":            "",
+		"keep.log":         "",
+		"top.txt":          "",
+		"build/x.go":       "",
+		"docs/readme.md":   "",
+		"vendor/lib.go":    "",
+		"sub/.gitignore":   "*.md\n!important.md\n",
+		"sub/a.md":         "",
+		"sub/important.md": "",
+		"sub/top.txt":      "",
+		"sub/build":        "", // 文件不会匹配只匹配目录的规则
+	}
+	for name, content := range files {
+		if err := root.Join(name).Write(content); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	return root
+}
+
+func TestIgnoreMatcher_Walk(t *testing.T) {
+	root := createIgnoreFileTree(t)
+	ignore := MustNewIgnoreMatcher(GitIgnoreFile, "vendor/")
+	var visited []string
+	err := root.Walk(func(path IPath, err error) error {
+		if err != nil {
+			return err
+		}
+		if !path.Equal(root) {
+			visited = append(visited, path.MustRelTo(root).AsPosix())
+		}
+		return nil
+	}, WalkOptions{Ignore: ignore})
+	if err != nil {
+		t.Fatalf("Failed to walk path: %v", err)
+	}
+	expected := []string{".gitignore", "a.md", "docs", "keep.log", "sub", "sub/.gitignore", "sub/build",
+		"sub/important.md", "sub/top.txt"}
+	slices.Sort(visited)
+	if !slices.Equal(visited, expected) {
+		t.Errorf("Expected %v, got %v", expected, visited)
+	}
+}
+
+func TestIgnoreMatcher_WalkLoadError(t *testing.T) {
+	root := createIgnoreFileTree(t)
+	// 无法加载的忽略文件，fn返回nil时不使用它继续遍历这个目录
+	if err := root.Join("sub", GitIgnoreFile).Write("a[b\n"); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+	walks := map[string]func(fn func(path IPath, err error) error) error{
+		"Walk": func(fn func(path IPath, err error) error) error {
+			return Walk(root, fn, WalkOptions{Ignore: MustNewIgnoreMatcher(GitIgnoreFile)})
+		},
+		"WalkParallel": func(fn func(path IPath, err error) error) error {
+			return WalkParallel(root, fn, WalkOptions{Ignore: MustNewIgnoreMatcher(GitIgnoreFile)})
+		},
+	}
+	for name, walk := range walks {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var visited, failed []string
+			err := walk(func(path IPath, err error) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if !errors.Is(err, ErrIgnoreRule) {
+						t.Errorf("Expected ErrIgnoreRule, got %v", err)
+					}
+					failed = append(failed, path.MustRelTo(root).AsPosix())
+					return nil
+				}
+				if !path.Equal(root) {
+					visited = append(visited, path.MustRelTo(root).AsPosix())
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Failed to walk path: %v", err)
+			}
+			if !slices.Equal(failed, []string{"sub"}) {
+				t.Errorf("Expected the ignore file of sub to fail, got %v", failed)
+			}
+			// 上级目录的规则仍然生效
+			expected := []string{"sub/.gitignore", "sub/a.md", "sub/build", "sub/important.md", "sub/top.txt"}
+			for _, name := range expected {
+				if !slices.Contains(visited, name) {
+					t.Errorf("Expected %s to be visited, got %v", name, visited)
+				}
+			}
+		})
+	}
+}
+
+func TestIgnoreMatcher_Ignored(t *testing.T) {
+	root := createIgnoreFileTree(t)
+	ignore := MustNewIgnoreMatcher(GitIgnoreFile)
+	testcases := []struct {
+		input  string
+		output bool
+	}{
+		{"a.md", false},
+		{"a.log", true},
+		{"keep.log", false},
+		{"top.txt", true},
+		{"sub/top.txt", false},
+		{"build", true},
+		{"build/x.go", true}, // 上级目录被忽略
+		{"sub/build", false},
+		{"docs", false},
+		{"docs/readme.md", true},
+		{"sub/a.md", true},
+		{"sub/important.md", false},
+		{"vendor/lib.go", false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			if result := ignore.MustIgnored(root, root.Join(tc.input)); result != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result)
+			}
+		})
+	}
+}
+
+func TestNewIgnoreMatcher(t *testing.T) {
+	ignore := MustNewIgnoreMatcher("", `\#x`, `\!y`, `a\ `, "{b,c}", "/d/**/e")
+	testcases := []struct {
+		input  string
+		output bool
+	}{
+		{"#x", true},
+		{"!y", true},
+		{"a ", true},
+		{"{b,c}", true},
+		{"b", false},
+		{"d/1/2/e", true},
+		{"x/d/e", false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			if result := ignoredBy(ignore.rootFrames(), strings.Split(tc.input, "/"), false); result != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result)
+			}
+		})
+	}
+	_, err := NewIgnoreMatcher("", "a[b")
+	if !errors.Is(err, ErrIgnoreRule) {
+		t.Errorf("Expected ErrIgnoreRule, got %v", err)
+	}
+}
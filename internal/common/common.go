@@ -5,6 +5,15 @@ import (
 	"fmt"
 )
 
+// 纯路径处理中各个风格共用的错误，ntpath 和 posixpath 都会重新导出
+var (
+	ErrNoName        = errors.New("no name provided")
+	ErrNotRelative   = errors.New("path is not relative to the other path")
+	ErrInvalidName   = errors.New("invalid name provided")
+	ErrInvalidAnchor = errors.New("invalid anchor provided")
+	ErrInvalidSuffix = errors.New("invalid suffix provided")
+)
+
 // 使用parent错误包装一个子错误，并添加格式信息描述父错误
 func WrapSub(err error, parent error, format string, a ...any) error {
 	return errors.Join(
@@ -0,0 +1,90 @@
+package path
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"os"
+
+	"github.com/viocha/go-pathlib/internal/common"
+	"github.com/viocha/go-pathlib/purepath"
+)
+
+const iterDirBatch = 256 // IterDir每次从目录中读取的数量
+
+var errIterStop = errors.New("iteration stopped") // 迭代的循环提前结束，用于终止内部的遍历
+
+// 逐个产生目录的子路径，每个路径都是 *Entry，每次只读取一批目录项，适合非常大的目录，不保证顺序。
+// 读取失败时产生一次 (root, err) 并结束
+func IterDir(root IPath) iter.Seq2[IPath, error] {
+	return func(yield func(IPath, error) bool) {
+		f, err := os.Open(root.String())
+		if err != nil {
+			yield(root, common.WrapSub(err, ErrReadDir, "failed to read directory: %q", root))
+			return
+		}
+		defer f.Close()
+		for {
+			entries, err := f.ReadDir(iterDirBatch)
+			for _, entry := range entries {
+				if !yield(newDirEntry(root, entry), nil) {
+					return
+				}
+			}
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(root, common.WrapSub(err, ErrReadDir, "failed to read directory: %q", root))
+				return
+			}
+		}
+	}
+}
+
+// 自顶向下逐个产生遍历到的路径，遍历出错时产生 (path, err)，循环可以选择继续或者结束。
+// 循环结束时遍历也会停止，可以使用 WalkOptions.Prune 跳过子目录
+func IterWalk(root IPath, walkOptions ...WalkOptions) iter.Seq2[IPath, error] {
+	return func(yield func(IPath, error) bool) {
+		_ = Walk(root, func(path IPath, err error) error {
+			if !yield(path, err) {
+				return errIterStop
+			}
+			return nil
+		}, walkOptions...)
+	}
+}
+
+// 逐个产生root下所有匹配的路径，和 Glob 相同，但是不会把结果保存到切片中。模式错误时产生一次 (root, err)
+func IterGlob(root IPath, pattern string, globOptions ...GlobOptions) iter.Seq2[IPath, error] {
+	options := common.ParseOptional(globOptions, GlobOptions{})
+	compiled, err := purepath.CompilePatternWithFlavour(root.Flavour(), pattern,
+		purepath.PatternOptions{CaseSensitive: options.CaseSensitive})
+	if err != nil {
+		return func(yield func(IPath, error) bool) {
+			yield(root, err)
+		}
+	}
+	return IterGlobPattern(root, compiled, options)
+}
+
+// 使用编译后的模式逐个产生root下所有匹配的路径
+func IterGlobPattern(root IPath, pattern *purepath.Pattern, globOptions ...GlobOptions) iter.Seq2[IPath, error] {
+	options := common.ParseOptional(globOptions, GlobOptions{})
+	return func(yield func(IPath, error) bool) {
+		w, err := newGlobWalker(root, pattern, options, func(path IPath, err error) error {
+			if errors.Is(err, ErrWalkCycle) && options.SkipOnCycle { // 检测到循环链接，并允许跳过
+				return nil
+			}
+			if !yield(path, err) {
+				return errIterStop
+			}
+			return nil
+		})
+		if err != nil {
+			yield(root, err)
+			return
+		}
+		_ = w.walkRecursively(root, nil, options.Ignore.rootFrames(), pattern.Start())
+	}
+}
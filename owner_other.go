@@ -0,0 +1,10 @@
+//go:build !unix
+
+package path
+
+import "io/fs"
+
+// 不支持所有者，复制时不会修改
+func fileOwner(info fs.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
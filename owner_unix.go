@@ -0,0 +1,17 @@
+//go:build unix
+
+package path
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// 文件的所有者和所属组
+func fileOwner(info fs.FileInfo) (uid, gid int, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
@@ -1,10 +1,13 @@
 package path
 
 import (
+	"context"
 	"errors"
+	"iter"
 	"net/url"
 	"os"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/viocha/go-pathlib/internal/common"
@@ -15,9 +18,22 @@ import (
 type MergeMode int
 
 const (
-	MergeModeError   MergeMode = iota // 返回错误
-	MergeModeSkip                     // 跳过冲突
-	MergeModeReplace                  // 替换冲突
+	MergeModeError    MergeMode = iota // 返回错误
+	MergeModeSkip                      // 跳过冲突
+	MergeModeReplace                   // 替换冲突
+	MergeModeNewer                     // 源路径的修改时间更新时替换，否则跳过
+	MergeModeLarger                    // 源路径更大时替换，否则跳过
+	MergeModeKeepBoth                  // 保留两者，复制的路径重命名为 name (1).ext
+	MergeModeCallback                  // 调用 CopyOptions.OnConflict 决定处理方式
+)
+
+// 合并时一个冲突的处理方式
+type MergeAction int
+
+const (
+	MergeActionSkip     MergeAction = iota // 跳过，保留目标路径
+	MergeActionReplace                     // 删除目标路径，再复制源路径
+	MergeActionKeepBoth                    // 保留两者，复制的路径重命名为 name (1).ext
 )
 
 var (
@@ -63,16 +79,33 @@ type IPath interface {
 	// 重命名、移动、复制。都不跟随符号链接，操作符号链接本身
 	Rename(newName string, replace ...bool) (IPath, error) // 使用Move方法实现
 	Move(dst IPath, replace ...bool) error                 // 支持文件，符号链接，文件夹，不支持合并文件夹，需要删除整个目标文件夹
-	Copy(dst IPath, replace ...bool) error                 // 支持文件，符号链接，文件夹，支持递归复制文件夹，但需要删除整个目标文件夹
-	CopyMerge(dst IPath, mergeMode ...MergeMode) error     // 使用合并方式，递归复制文件夹，支持跳过冲突或覆盖文件，默认返回错误
-	MoveMerge(dst IPath, mergeMode ...MergeMode) error     // 使用合并方式，递归移动文件夹，支持跳过冲突或覆盖文件，默认返回错误
+	Copy(dst IPath, copyOptions ...CopyOptions) error      // 支持文件，符号链接，文件夹，支持递归复制文件夹，但需要删除整个目标文件夹，可以保留权限、时间等元数据
+	CopyMerge(dst IPath, copyOptions ...CopyOptions) error // 使用合并方式，递归复制文件夹，通过MergeMode处理冲突的文件，默认返回错误
+	MoveMerge(dst IPath, copyOptions ...CopyOptions) error // 使用合并方式，递归移动文件夹，选项和 CopyMerge 相同，但是不支持过滤
+	// 可以取消的版本，通过 CopyOptions.Progress 报告进度，取消时返回ctx的错误
+	CopyContext(ctx context.Context, dst IPath, copyOptions ...CopyOptions) error
+	CopyMergeContext(ctx context.Context, dst IPath, copyOptions ...CopyOptions) error
+	MoveMergeContext(ctx context.Context, dst IPath, copyOptions ...CopyOptions) error
 
 	// 目录读取和遍历
-	ReadDir() ([]IPath, error)
-	// 使用通配符的读取所有匹配的路径，支持**，默认不跟随符号链接，不跳过循环的链接，而是返回错误
+	ReadDir() ([]IPath, error)         // 返回的每个路径都是 *Entry
+	ReadDirEntries() ([]*Entry, error) // 读取子路径，并缓存目录项的类型和文件信息
+	// 使用通配符的读取所有匹配的路径，模式相对于当前路径，支持**，默认不跟随符号链接，不跳过循环的链接，而是返回错误
 	Glob(pattern string, globOptions ...GlobOptions) ([]IPath, error)
-	// 自顶向下遍历目录，fn返回nil表示继续遍历，WalkSkip表示跳过当前目录的向下遍历，WalkStop表示终止遍历
-	Walk(fn func(path IPath, err error) error, follow ...bool) error
+	// 递归读取所有匹配的路径，等同于 Glob("**/" + pattern)
+	RGlob(pattern string, globOptions ...GlobOptions) ([]IPath, error)
+	// 使用编译后的模式读取所有匹配的路径，适合重复使用同一个模式
+	GlobPattern(pattern *purepath.Pattern, globOptions ...GlobOptions) ([]IPath, error)
+	// 自顶向下遍历目录，fn返回nil表示继续遍历，WalkSkip表示跳过当前目录的向下遍历，WalkStop表示终止遍历，
+	// 默认不跟随符号链接，可以通过WalkOptions指定忽略规则、深度、遍历顺序和出错时的处理方式
+	Walk(fn func(path IPath, err error) error, walkOptions ...WalkOptions) error
+
+	// 迭代器版本，循环结束时会停止读取
+	IterDir() iter.Seq2[IPath, error]                                            // 逐批读取目录，不保证顺序
+	IterGlob(pattern string, globOptions ...GlobOptions) iter.Seq2[IPath, error] // 和Glob相同，逐个产生匹配的路径
+	IterWalk(walkOptions ...WalkOptions) iter.Seq2[IPath, error]                 // 和Walk相同，出错时产生 (path, err)
+	// 类似Python的 Path.walk，产生每个目录和它的子目录名称、文件名称，支持自底向上
+	WalkDirs(walkDirsOptions ...WalkDirsOptions) iter.Seq2[*WalkDirsEntry, error]
 
 	// panic版本的方法
 	MustToURL() string
@@ -91,24 +124,79 @@ type IPath interface {
 	MustRename(newName string, replace ...bool) IPath
 	MustReadDir() []IPath
 	MustGlob(pattern string, globOptions ...GlobOptions) []IPath
+	MustRGlob(pattern string, globOptions ...GlobOptions) []IPath
+	MustGlobPattern(pattern *purepath.Pattern, globOptions ...GlobOptions) []IPath
 }
 
 var (
 	ErrParseURL = errors.New("failed to parse URL")
 )
 
+var (
+	ErrToURL      = errors.New("failed to convert to URL")
+	ErrToAbs      = errors.New("failed to convert to absolute path")
+	ErrReadLink   = errors.New("failed to read symlink")
+	ErrResolve    = errors.New("failed to resolve path")
+	ErrReadStat   = errors.New("failed to read file status")
+	ErrReadLstat  = errors.New("failed to read file status without following symlink")
+	ErrOpen       = errors.New("failed to open file")
+	ErrCreate     = errors.New("failed to create file")
+	ErrMkdir      = errors.New("failed to create directory")
+	ErrSymlink    = errors.New("failed to create symlink")
+	ErrRead       = errors.New("failed to read file")
+	ErrWrite      = errors.New("failed to write file")
+	ErrEnsureDir  = errors.New("failed to ensure directory exists")
+	ErrEnsureFile = errors.New("failed to ensure file exists")
+	ErrRemove     = errors.New("failed to remove file or directory")
+	ErrRename     = errors.New("failed to rename file or directory")
+	ErrMove       = errors.New("failed to move file or directory")
+	ErrCopy       = errors.New("failed to copy file or directory")
+	ErrCopyMerge  = errors.New("failed to copy-merge file or directory")
+	ErrReadDir    = errors.New("failed to read directory")
+)
+
+// 根据当前操作系统创建路径对象，Windows上使用 WindowsPath，其他系统使用 PosixPath
 func New(segments ...string) IPath {
 	if runtime.GOOS == "windows" {
 		return NewWindowsPath(segments...)
 	}
-	panic("path package is not implemented for this OS")
+	return NewPosixPath(segments...)
 }
 
+// 从纯路径对象创建路径对象，根据纯路径的风格选择 WindowsPath 或 PosixPath，不会重新解析路径字符串。
+// 风格和当前系统无关，例如Linux上的Windows纯路径仍然得到 WindowsPath，需要当前系统的风格时先调用 ToPosix / ToWindows
 func FromPurePath(purePath purepath.IPurePath) IPath {
 	if purePath == nil { // 如果传入的纯路径是 nil，返回 nil
 		return nil
 	}
-	return New(purePath.String())
+	base := &BasePath{IPurePath: purePath}
+	if purePath.Flavour() == purepath.FlavourWindows {
+		return WindowsPath{BasePath: base}
+	}
+	return PosixPath{BasePath: base}
+}
+
+// 按照 Compare 的规则对路径进行稳定排序，相等的路径保持原有的顺序
+func SortPaths(paths []IPath) {
+	slices.SortStableFunc(paths, func(a, b IPath) int {
+		if a == nil { // nil排在最前面
+			if b == nil {
+				return 0
+			}
+			return -1
+		}
+		return a.Compare(b)
+	})
+}
+
+// 返回所有路径的最长公共路径，路径的风格必须相同，并且必须都是绝对路径或者都是相对路径
+func CommonPath(paths ...IPath) (IPath, error) {
+	commonPath, err := purepath.CommonPath(toPurePaths(paths)...)
+	return FromPurePath(commonPath), err
+}
+
+func MustCommonPath(paths ...IPath) IPath {
+	return FromPurePath(purepath.MustCommonPath(toPurePaths(paths)...))
 }
 
 // 从文件URL创建 Path
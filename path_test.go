@@ -1,6 +1,15 @@
 package path
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/viocha/go-pathlib/purepath"
+)
 
 func TestFromURL(t *testing.T) {
 	// 测试从 file URL 创建 Path
@@ -10,6 +19,15 @@ func TestFromURL(t *testing.T) {
 	}{
 		{"file:///C:/path/to/file.txt", `C:\path\to\file.txt`},
 	}
+	if runtime.GOOS != "windows" {
+		testCases = []struct {
+			url      string
+			expected string
+		}{
+			{"file:///path/to/file.txt", `/path/to/file.txt`},
+			{"file:///path/to/a%20b.txt", `/path/to/a b.txt`},
+		}
+	}
 
 	for _, tc := range testCases {
 		t.Run(tc.url, func(t *testing.T) {
@@ -24,3 +42,144 @@ func TestFromURL(t *testing.T) {
 		})
 	}
 }
+
+func TestFromPurePath(t *testing.T) {
+	// 测试 FromPurePath 保留纯路径的风格
+	testCases := []struct {
+		pure     purepath.IPurePath
+		expected string
+		windows  bool
+	}{
+		{purepath.NewWindows(`c:\foo`, "bar"), `c:\foo\bar`, true},
+		{purepath.NewPosix("/foo", "bar"), "/foo/bar", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			path := FromPurePath(tc.pure)
+			if path.String() != tc.expected {
+				t.Errorf("Expected path %s, got %s", tc.expected, path.String())
+			}
+			if _, ok := path.(WindowsPath); ok != tc.windows {
+				t.Errorf("Expected WindowsPath = %v, got %T", tc.windows, path)
+			}
+			if path.ToPurePath().Flavour() != tc.pure.Flavour() {
+				t.Errorf("Expected flavour %v, got %v", tc.pure.Flavour(), path.ToPurePath().Flavour())
+			}
+			// 派生出的路径也保留风格
+			if _, ok := path.Join("baz").(WindowsPath); ok != tc.windows {
+				t.Errorf("Expected joined WindowsPath = %v, got %T", tc.windows, path.Join("baz"))
+			}
+		})
+	}
+}
+
+func TestSortPaths(t *testing.T) {
+	paths := []IPath{NewWindowsPath(`c:\b`), NewWindowsPath(`C:\A`), NewWindowsPath(`c:\a`)}
+	SortPaths(paths)
+	if paths[0].String() != `C:\A` || paths[1].String() != `c:\a` || paths[2].String() != `c:\b` {
+		t.Errorf("Unexpected order: %v", paths)
+	}
+	if !paths[0].Equal(paths[1]) || paths[0].Equal(paths[2]) {
+		t.Errorf("Unexpected equality for %v", paths)
+	}
+}
+
+func TestCommonPath(t *testing.T) {
+	common, err := CommonPath(NewPosixPath("/src/a/b"), NewPosixPath("/src/a/c"), NewPosixPath("/src/ab"))
+	if err != nil || common.String() != "/src" {
+		t.Errorf("Expected /src, got %v, %v", common, err)
+	}
+	if !common.IsAncestorOf(NewPosixPath("/src/ab")) || common.IsAncestorOf(NewPosixPath("/srcx")) {
+		t.Errorf("Unexpected ancestry for %v", common)
+	}
+	if _, err := CommonPath(NewPosixPath("/a"), NewPosixPath("a")); err == nil {
+		t.Errorf("Expected error for absolute and relative paths")
+	}
+}
+
+func TestWalkParallel(t *testing.T) {
+	root := New("./file/dir")
+	collect := func(options WalkOptions, skip string) ([]string, error) {
+		var mu sync.Mutex
+		var result []string
+		err := WalkParallel(root, func(path IPath, err error) error {
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			result = append(result, path.MustRelTo(root).AsPosix())
+			if path.Name() == skip {
+				return WalkSkip
+			}
+			return nil
+		}, options)
+		slices.Sort(result)
+		return result, err
+	}
+
+	t.Run("same paths as Walk", func(t *testing.T) {
+		var expected []string
+		_ = root.Walk(func(path IPath, err error) error {
+			expected = append(expected, path.MustRelTo(root).AsPosix())
+			return err
+		})
+		slices.Sort(expected)
+		for _, workers := range []int{1, 4} {
+			result, err := collect(WalkOptions{Workers: workers}, "")
+			if err != nil || !slices.Equal(result, expected) {
+				t.Errorf("Workers %d: expected %v, got %v, %v", workers, expected, result, err)
+			}
+		}
+	})
+	t.Run("skip", func(t *testing.T) {
+		result, err := collect(WalkOptions{Workers: 2}, "sub")
+		if expected := []string{".", "a.md", "b.md", "sub"}; err != nil || !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v, %v", expected, result, err)
+		}
+	})
+	t.Run("follow link", func(t *testing.T) {
+		// 跟随链接时路径仍然位于链接下面
+		link := New("./file/ldir")
+		var mu sync.Mutex
+		var result []string
+		err := WalkParallel(link, func(path IPath, err error) error {
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			result = append(result, path.String())
+			return nil
+		}, WalkOptions{Follow: true, Workers: 2})
+		slices.Sort(result)
+		expected := []string{link.String(), link.Join("a.md").String(), link.Join("b.md").String(),
+			link.Join("sub").String(), link.Join("sub", "x.md").String(), link.Join("sub", "y.md").String()}
+		slices.Sort(expected)
+		if err != nil || !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v, %v", expected, result, err)
+		}
+	})
+	t.Run("stop", func(t *testing.T) {
+		err := WalkParallel(root, func(path IPath, err error) error {
+			if path.Name() == "sub" {
+				return WalkStop
+			}
+			return err
+		})
+		if !errors.Is(err, WalkStop) {
+			t.Errorf("Expected WalkStop, got %v", err)
+		}
+	})
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := WalkParallelContext(ctx, root, func(path IPath, err error) error {
+			cancel()
+			return err
+		}, WalkOptions{Workers: 2})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package path
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+
+	"github.com/viocha/go-pathlib/internal/common"
+	"github.com/viocha/go-pathlib/purepath"
+)
+
+type PosixPath struct {
+	*BasePath
+}
+
+// 确保实现了 IPath 接口
+var _ IPath = (*PosixPath)(nil)
+
+func NewPosixPath(segments ...string) PosixPath {
+	return PosixPath{
+		BasePath: &BasePath{IPurePath: purepath.NewPurePosixPath(segments...)},
+	}
+}
+
+// 转换成URL，返回 file:///path 格式，路径中的特殊字符会被转义
+func (p PosixPath) ToURL() (string, error) {
+	absPath, err := p.ToAbs()
+	if err != nil {
+		return "", common.WrapSub(err, ErrToURL, "failed to convert to absolute path: %q", p)
+	}
+	u := url.URL{Scheme: "file", Path: absPath.String()}
+	return u.String(), nil
+}
+
+// 转换成绝对路径，并解析路径中所有的符号链接，返回最终的目标路径，目标路径必须存在
+func (p PosixPath) Resolve() (IPath, error) {
+	absPath, err := p.ToAbs()
+	if err != nil {
+		return nil, errors.Join(ErrResolve, err)
+	}
+	resolvedPath, err := filepath.EvalSymlinks(absPath.String())
+	if err != nil {
+		return nil, common.WrapSub(err, ErrResolve, "failed to resolve symlinks: %q", absPath)
+	}
+	return NewPosixPath(resolvedPath), nil
+}
+
+// ======================== panic版本的方法 ========================
+
+func (p PosixPath) MustToURL() string {
+	url, err := p.ToURL()
+	if err != nil {
+		panic(err)
+	}
+	return url
+}
+
+// 返回解析后的路径，如果失败则 panic
+func (p PosixPath) MustResolve() IPath {
+	resolvedPath, err := p.Resolve()
+	if err != nil {
+		panic(err)
+	}
+	return resolvedPath
+}
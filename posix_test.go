@@ -0,0 +1,1363 @@
+//go:build !windows
+
+package path
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/viocha/go-pathlib/internal/common"
+	"github.com/viocha/go-pathlib/purepath"
+)
+
+func createTestFileTree(base string) error {
+	/*
+		./file
+				├── empty
+				├── dir
+				│   ├── sub
+				│   │   ├── x.md
+				│   │   └── y.md
+				│   ├── a.md
+				│   └── b.md
+				├── ldir (目录符号链接 -> dir)
+				├── lnodir (目录符号链接 -> 不存在的文件 /nonexistent/nodir)
+				├── f.md
+				├── lnof.md (文件符号链接 -> 不存在的文件 /nonexistent/nofile)
+				└── lf.md (文件符号链接 -> f.md)
+	*/
+
+	// 清理已经存在的file目录
+	if err := cleanupTestFileTree(base); err != nil {
+		return fmt.Errorf("failed to clean up existing test directory: %w", err)
+	}
+
+	// 创建目录结构
+	paths := []string{
+		filepath.Join(base, "empty"),
+		filepath.Join(base, "dir", "sub"),
+	}
+	for _, p := range paths {
+		if err := os.MkdirAll(p, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	// 创建文件
+	files := map[string]string{
+		filepath.Join(base, "f.md"):               "f.md content",
+		filepath.Join(base, "dir", "a.md"):        "a.md content",
+		filepath.Join(base, "dir", "b.md"):        "b.md content",
+		filepath.Join(base, "dir", "sub", "x.md"): "x.md content",
+		filepath.Join(base, "dir", "sub", "y.md"): "y.md content",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	// 创建文件符号链接 lf -> f.md
+	fPath := filepath.Join("f.md") // 相对于链接的路径，而不是工作目录
+	// fPath, _ = filepath.Abs(fPath) // 指向绝对路径
+	lfPath := filepath.Join(base, "lf.md")
+	if err := os.Symlink(fPath, lfPath); err != nil {
+		return err
+	}
+
+	// 创建目录符号链接 ldir -> dir
+	dirPath := filepath.Join("dir") // 相对于链接的路径，而不是工作目录
+	// dirPath, _ = filepath.Abs(dirPath) // 指向绝对路径
+	ldirPath := filepath.Join(base, "ldir")
+	if err := os.Symlink(dirPath, ldirPath); err != nil {
+		return err
+	}
+
+	// 创建不存在的目录符号链接 lnodir -> /nonexistent/nodir
+	lnodirPath := filepath.Join(base, "lnodir")
+	lnodirTarget := `/nonexistent/nodir`
+	if err := os.Symlink(lnodirTarget, lnodirPath); err != nil {
+		return err
+	}
+
+	// 创建不存在的文件符号链接 lnof.md -> /nonexistent/nofile
+	lnofPath := filepath.Join(base, "lnof.md")
+	lnofTarget := `/nonexistent/nofile`
+	if err := os.Symlink(lnofTarget, lnofPath); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func cleanupTestFileTree(base string) error {
+	return os.RemoveAll(base)
+}
+
+func TestMain(m *testing.M) {
+	base := "./file"
+
+	// 创建用于测试的文件树
+	if err := createTestFileTree(base); err != nil {
+		if err := cleanupTestFileTree(base); err != nil {
+			fmt.Printf("创建失败后，清理测试文件树也失败: %v\n", err)
+		}
+		panic(fmt.Sprintf("创建测试文件树失败: %v", err))
+	}
+
+	// 执行测试
+	code := m.Run()
+
+	// 清理测试文件
+	if err := cleanupTestFileTree(base); err != nil {
+		panic(fmt.Sprintf("清理测试文件树失败: %v", err))
+	}
+
+	os.Exit(code)
+}
+
+// 返回当前工作目录，用于计算绝对路径的期望值
+func mustGetwd(t *testing.T) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	return wd
+}
+
+func TestPosixPath_MustToAbs(t *testing.T) {
+	wd := mustGetwd(t)
+	testcases := []struct {
+		path     string
+		expected string
+	}{
+		{`./file/f.md`, wd + `/file/f.md`},
+		{`./file/lf.md`, wd + `/file/lf.md`},
+		{`/usr/../etc`, `/etc`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			absPath := p.MustToAbs()
+			if absPath.String() != tc.expected {
+				t.Errorf("Expected absolute path %s, got %s", tc.expected, absPath.String())
+			}
+		})
+	}
+}
+
+func TestPosixPath_MustToURL(t *testing.T) {
+	wd := mustGetwd(t)
+	testcases := []struct {
+		path     string
+		expected string
+	}{
+		{`./file/f.md`, `file://` + wd + `/file/f.md`},
+		{`/tmp/a b.md`, `file:///tmp/a%20b.md`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			url := p.MustToURL()
+			if url != tc.expected {
+				t.Errorf("Expected absolute path %s, got %s", tc.expected, url)
+			}
+			if back := MustURLToPath(url); back != NewPosixPath(tc.path).MustToAbs().String() {
+				t.Errorf("Expected URL %s to convert back to %s, got %s", url, p.MustToAbs(), back)
+			}
+		})
+	}
+}
+
+func TestPosixPath_MustReadLink(t *testing.T) {
+	testcases := []struct {
+		path         string
+		expected     string
+		expectedPath string
+	}{
+		{`./file/ldir`, `dir`, `file/dir`},
+		{`./file/lf.md`, `f.md`, `file/f.md`},
+		{`./file/lnodir`, `/nonexistent/nodir`, `/nonexistent/nodir`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			target := p.MustReadLink()
+			if target.String() != tc.expected {
+				t.Errorf("Expected target path %s, got %s", tc.expected, target.String())
+			}
+			targetPath := p.MustReadLinkPath()
+			if targetPath.String() != tc.expectedPath {
+				t.Errorf("Expected joined target path %s, got %s", tc.expectedPath, targetPath.String())
+			}
+		})
+	}
+}
+
+func TestPosixPath_MustResolve(t *testing.T) {
+	wd, err := filepath.EvalSymlinks(mustGetwd(t))
+	if err != nil {
+		t.Fatalf("Failed to resolve working directory: %v", err)
+	}
+	testcases := []struct {
+		path     string
+		expected string
+	}{
+		{`./file/f.md`, wd + `/file/f.md`},
+		{`./file/lf.md`, wd + `/file/f.md`},
+		{`./file/ldir/sub`, wd + `/file/dir/sub`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			resolved := p.MustResolve()
+			if resolved.String() != tc.expected {
+				t.Errorf("Expected resolved path %s, got %s", tc.expected, resolved.String())
+			}
+		})
+	}
+}
+
+func TestPosixPath_Exists(t *testing.T) {
+	testcases := []struct {
+		path   string
+		follow bool
+		exists bool
+	}{
+		{`./file/nonexist`, false, false},
+		{`./file/lnof.md`, false, true},
+		{`./file/lnof.md`, true, false},
+		{`./file/lnodir`, true, false},
+		{`./file/lnodir`, false, true},
+
+		{`./file/f.md`, false, true},
+		{`./file/lf.md`, false, true},
+		{`./file/lf.md`, true, true},
+		{`./file/dir`, false, true},
+		{`./file/ldir`, false, true},
+		{`./file/ldir`, true, true},
+		{`./file/empty`, false, true},
+	}
+	for _, tc := range testcases {
+		t.Run(fmt.Sprintf("Path: %s, Follow: %t", tc.path, tc.follow), func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			exists := p.Exists(tc.follow)
+			if exists != tc.exists {
+				t.Errorf("Expected Exists to be %t, got %t", tc.exists, exists)
+			}
+		})
+	}
+}
+
+func TestPosixPath_IsDir(t *testing.T) {
+	testcases := []struct {
+		path   string
+		follow bool
+		isDir  bool
+	}{
+		{`./file/dir`, false, true},
+		{`./file/empty`, false, true},
+		{`./file/nonexist`, false, false},
+		{`./file/ldir`, false, false},
+		{`./file/ldir`, true, true},
+	}
+	for _, tc := range testcases {
+		t.Run(fmt.Sprintf("Path: %s, Follow: %t", tc.path, tc.follow), func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			isDir := p.IsDir(tc.follow)
+			if isDir != tc.isDir {
+				t.Errorf("Expected IsDir to be %t, got %t", tc.isDir, isDir)
+			}
+		})
+	}
+}
+
+func TestPosixPath_IsFile(t *testing.T) {
+	testcases := []struct {
+		path   string
+		follow bool
+		isFile bool
+	}{
+		{`./file/nonexist`, false, false},
+		{`./file/f.md`, false, true},
+		{`./file/lf.md`, false, false},
+		{`./file/lf.md`, true, true},
+		{`./file/dir`, false, false},
+		{`./file/ldir`, false, false},
+		{`./file/ldir`, true, false},
+		{`./file/empty`, false, false},
+	}
+	for _, tc := range testcases {
+		t.Run(fmt.Sprintf("Path: %s, Follow: %t", tc.path, tc.follow), func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			isFile := p.IsFile(tc.follow)
+			if isFile != tc.isFile {
+				t.Errorf("Expected IsFile to be %t, got %t", tc.isFile, isFile)
+			}
+		})
+	}
+}
+
+func TestPosixPath_IsSymlink(t *testing.T) {
+	testcases := []struct {
+		path      string
+		isSymlink bool
+	}{
+		{`./file/nonexist`, false},
+		{`./file/ldir`, true},
+		{`./file/lf.md`, true},
+		{`./file/dir`, false},
+		{`./file/f.md`, false},
+	}
+	for _, tc := range testcases {
+		t.Run(fmt.Sprintf("Path: %s", tc.path), func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			isSymlink := p.IsLink()
+			if isSymlink != tc.isSymlink {
+				t.Errorf("Expected IsSymlink to be %t, got %t", tc.isSymlink, isSymlink)
+			}
+		})
+	}
+}
+
+func TestPosixPath_SameFile(t *testing.T) {
+	testcases := []struct {
+		path1 string
+		path2 string
+		same  bool
+	}{
+		{`./file/f.md`, `./file/lf.md`, true},
+		{`./file/ldir`, `./file/dir`, true},
+		{`./file/dir/a.md`, `./file/dir/b.md`, false},
+		{`./file/dir/a.md`, `./file/ldir/a.md`, true},
+		{`./file/dir/a.md`, `./file/ldir/b.md`, false},
+	}
+	for _, tc := range testcases {
+		t.Run(fmt.Sprintf("Path1: %s, Path2: %s", tc.path1, tc.path2), func(t *testing.T) {
+			p1 := NewPosixPath(tc.path1)
+			p2 := NewPosixPath(tc.path2)
+			same := p1.SameFile(p2)
+			if same != tc.same {
+				t.Errorf("Expected SameFile to be %t, got %t", tc.same, same)
+			}
+		})
+	}
+}
+
+func TestPosixPath_MustOpen(t *testing.T) {
+	testcases := []struct {
+		path     string
+		expected string
+	}{
+		{`./file/f.md`, `f.md content`},
+		{`./file/lf.md`, `f.md content`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			file := p.MustOpen()
+			defer func() {
+				if err := file.Close(); err != nil {
+					t.Fatalf("Failed to close file: %v", err)
+				}
+			}()
+
+			content := make([]byte, 1024)
+			n, err := file.Read(content)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+				return
+			}
+
+			if string(content[:n]) != tc.expected {
+				t.Errorf("Expected file content %s, got %s", tc.expected, string(content[:n]))
+			}
+		})
+	}
+}
+
+func TestPosixPath_MustRead(t *testing.T) {
+	testcases := []struct {
+		path     string
+		expected string
+	}{
+		{`./file/f.md`, `f.md content`},
+		{`./file/lf.md`, `f.md content`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			content := p.MustRead()
+			if content != tc.expected {
+				t.Errorf("Expected file content %s, got %s", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestPosixPath_Write(t *testing.T) {
+	testcases := []struct {
+		path     string
+		content  string
+		expected string
+	}{
+		{`./file/f.md`, `new content`, `new content`},
+		{`./file/lf.md`, `new content`, `new content`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			_ = p.Write(tc.content)
+			content := p.MustRead()
+			if content != tc.expected {
+				t.Errorf("Expected file content %s, got %s", tc.expected, content)
+			}
+			_ = p.Write("f.md content") // 恢复原内容
+		})
+	}
+}
+
+func TestPosixPath_MustReadBytes(t *testing.T) {
+	testcases := []struct {
+		path     string
+		expected []byte
+	}{
+		{`./file/f.md`, []byte(`f.md content`)},
+		{`./file/lf.md`, []byte(`f.md content`)},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			content := p.MustReadBytes()
+			if string(content) != string(tc.expected) {
+				t.Errorf("Expected file content %s, got %s", string(tc.expected), string(content))
+			}
+		})
+	}
+}
+
+func TestPosixPath_WriteBytes(t *testing.T) {
+	testcases := []struct {
+		path     string
+		content  []byte
+		expected []byte
+	}{
+		{`./file/f.md`, []byte(`new byte content`), []byte(`new byte content`)},
+		{`./file/lf.md`, []byte(`new byte content`), []byte(`new byte content`)},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			_ = p.WriteBytes(tc.content)
+			content := p.MustReadBytes()
+			if string(content) != string(tc.expected) {
+				t.Errorf("Expected file content %s, got %s", string(tc.expected), string(content))
+			}
+			_ = p.WriteBytes([]byte("f.md content")) // 恢复原内容
+		})
+	}
+}
+
+func TestPosixPath_Create(t *testing.T) {
+	testcases := []struct {
+		path    string
+		parents bool
+	}{
+		{`./file/newfile.md`, false},
+		{`./file/newdir/newfile.md`, true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			err := p.Create(tc.parents)
+			if err != nil {
+				t.Fatalf("Failed to create path: %v", err)
+			}
+			_ = os.RemoveAll(p.String()) // 清理创建的文件或目录
+		})
+	}
+	_ = os.RemoveAll("./file/newdir") // 清理创建的目录
+}
+
+func TestPosixPath_Mkdir(t *testing.T) {
+	testcases := []struct {
+		path    string
+		parents bool
+	}{
+		{`./file/newdir`, false},
+		{`./file/newdir/subdir`, true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			err := p.Mkdir(tc.parents)
+			if err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+			_ = os.RemoveAll(p.String()) // 清理创建的目录
+		})
+	}
+}
+
+func TestPosixPath_Symlink(t *testing.T) {
+	testcases := []struct {
+		path    string
+		parents bool
+		target  string
+	}{
+		{`./file/link-to-file`, false, `./f.md`},
+		{`./file/newdir1234/link-to-file`, true, `./f.md`},
+		{`./file/link-to-dir`, false, `./dir`},
+		{`./file/newdir4567/link-to-dir`, true, `./dir`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			targetPath := NewPosixPath(tc.target)
+			err := p.Symlink(targetPath, tc.parents)
+			if err != nil {
+				t.Fatalf("Failed to create symlink: %v", err)
+			}
+			_ = os.Remove(p.String()) // 清理创建的符号链接
+		})
+	}
+}
+
+func TestPosixPath_Remove(t *testing.T) {
+	testcases := []struct {
+		path      string
+		recursive bool
+	}{
+		{`./file/newfile.md`, false},       // 删除空目录
+		{`./file/newdir/newfile.md`, true}, // 递归删除目录
+	}
+	// 创建测试文件和目录
+	allErrors := errors.Join(
+		os.MkdirAll(`./file/newdir`, os.ModePerm),
+		os.WriteFile(`./file/newfile.md`, []byte("test content"), os.ModePerm),
+		os.WriteFile(`./file/newdir/newfile.md`, []byte("test content"), os.ModePerm),
+	)
+	if allErrors != nil {
+		t.Fatalf("Failed to create test files: %v", allErrors)
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			err := p.Remove(tc.recursive)
+			if err != nil {
+				t.Fatalf("Failed to remove path: %v", err)
+			}
+			if p.Exists(false) { // 确认路径已被删除
+				t.Errorf("Path %s still exists after removal", p.String())
+			}
+		})
+	}
+}
+
+func TestPosixPath_RemoveLink(t *testing.T) {
+	testcases := []struct {
+		path      string
+		recursive bool
+	}{
+		{`./file/file-link.md`, false}, // 删除符号链接文件
+		{`./file/dir-link`, false},     // 删除符号链接目录
+	}
+	// 创建测试符号链接
+	_ = NewPosixPath(`./file/file-link.md`).Symlink(NewPosixPath(`./f.md`))
+	_ = NewPosixPath(`./file/dir-link`).Symlink(NewPosixPath(`./dir`))
+
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			truePath := p.MustResolve()
+			err := p.Remove(tc.recursive)
+			if err != nil {
+				t.Fatalf("Failed to remove path: %v", err)
+			}
+			if p.Exists(false) { // 确认路径已被删除
+				t.Errorf("Path %s still exists after removal", p.String())
+			}
+			if !truePath.Exists(false) { // 确认真实路径仍然存在
+				t.Errorf("True path %s does not exist after removing symlink %s", truePath.String(), p.String())
+			}
+		})
+	}
+}
+
+func TestPosixPath_MustRename(t *testing.T) {
+	testcases := []struct {
+		path    string
+		newName string
+	}{
+		{`./file/f.md`, `new_f.md`},
+		{`./file/lf.md`, `new_lffff.md`},
+		{`./file/dir`, `new_dir`},
+	}
+	for _, tc := range testcases {
+		t.Run(fmt.Sprintf("Path: %s, NewName: %s", tc.path, tc.newName), func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			newPath := p.MustRename(tc.newName)
+			fmt.Printf("%q => %q\n", p, newPath)
+			if !newPath.Exists(false) {
+				t.Errorf("New path %s does not exist after renaming", newPath.String())
+			}
+			if p.Exists(false) {
+				t.Errorf("Original path %s still exists after renaming", p.String())
+			}
+			err := newPath.Move(p) // 恢复原路径
+			if err != nil {
+				t.Fatalf("Failed to restore original path: %v", err)
+			}
+		})
+	}
+}
+
+func TestPosixPath_Move(t *testing.T) {
+	testcases := []struct {
+		path    string
+		newPath string
+	}{
+		{`./file/f.md`, `./file/new_fff.md`},
+		{`./file/lf.md`, `./file/new_lfff.md`},
+		{`./file/dir`, `./file/new_dirrrr`},
+	}
+	for _, tc := range testcases {
+		t.Run(fmt.Sprintf("Path: %s, NewPath: %s", tc.path, tc.newPath), func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			newP := NewPosixPath(tc.newPath)
+			if !p.Exists(false) {
+				t.Fatalf("Original path %s does not exist", p.String())
+			}
+			err := p.Move(newP, false)
+			if err != nil {
+				t.Fatalf("Failed to move path: %v", err)
+			}
+			if !newP.Exists(false) {
+				t.Errorf("New path %s does not exist after moving", newP.String())
+			}
+			if p.Exists(false) {
+				t.Errorf("Original path %s still exists after moving", p.String())
+			}
+			err = newP.Move(p) // 恢复原路径
+			if err != nil {
+				t.Fatalf("Failed to restore original path: %v", err)
+			}
+		})
+	}
+}
+
+func TestPosixPath_MoveDir(t *testing.T) {
+	testcases := []struct {
+		path    string
+		newPath string
+	}{
+		{`./file/dir`, `./file/new_dir`},
+	}
+	for _, tc := range testcases {
+		t.Run(fmt.Sprintf("Path: %s, NewPath: %s", tc.path, tc.newPath), func(t *testing.T) {
+			p := NewPosixPath(tc.path)
+			newP := NewPosixPath(tc.newPath)
+			err := p.Move(newP, false)
+			if err != nil {
+				t.Fatalf("Failed to move path: %v", err)
+			}
+			if !newP.Exists(false) {
+				t.Errorf("New path %s does not exist after moving", newP.String())
+			}
+			if !newP.Join("a.md").Exists(false) {
+				t.Errorf("New path %s does not contain expected file after moving", newP.String())
+			}
+			if p.Exists(false) {
+				t.Errorf("Original path %s still exists after moving", p.String())
+			}
+			_ = os.Rename(newP.String(), p.String()) // 恢复原路径
+		})
+	}
+}
+
+func TestPosixPath_Copy(t *testing.T) {
+	src := NewPosixPath(`./file/dir`)
+	dst := NewPosixPath(`./file/copy_dir`)
+	err := src.Copy(dst)
+	if err != nil {
+		t.Fatalf("Failed to copy directory: %v", err)
+	}
+	if !dst.Join("a.md").Exists() {
+		t.Errorf("Copied directory %s does not contain expected file", dst.String())
+	}
+	if dst.Join("b.md").MustRead() != "b.md content" {
+		t.Errorf("Copied directory %s does not contain expected file content", dst.String())
+	}
+	if !src.Join("a.md").Exists() {
+		t.Errorf("Source directory %s does not contain expected file after copy", src.String())
+	}
+	if src.Join("b.md").MustRead() != "b.md content" {
+		t.Errorf("Source directory %s does not contain expected file content", dst.String())
+	}
+}
+
+func TestPosixPath_CopyContext(t *testing.T) {
+	src := NewPosixPath(`./file/dir`)
+	t.Run("progress", func(t *testing.T) {
+		dst := NewPosixPath(`./file/progress_copy`)
+		defer func() {
+			_ = dst.Remove()
+		}()
+		var last CopyProgress
+		err := src.CopyContext(context.Background(), dst, CopyOptions{Progress: func(progress CopyProgress) {
+			last = progress
+		}})
+		if err != nil {
+			t.Fatalf("Failed to copy directory: %v", err)
+		}
+		if last.TotalFiles != 4 || last.Files != last.TotalFiles || last.Bytes != last.TotalBytes {
+			t.Errorf("Expected all 4 files to be done, got %+v", last)
+		}
+	})
+	t.Run("cancel", func(t *testing.T) {
+		dst := NewPosixPath(`./file/canceled_copy`)
+		defer func() {
+			_ = dst.Remove()
+		}()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		files := 0
+		err := src.CopyContext(ctx, dst, CopyOptions{Progress: func(progress CopyProgress) {
+			files = progress.Files
+			if progress.Files == 1 {
+				cancel() // 第一个文件完成后取消
+			}
+		}})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		if files != 1 {
+			t.Errorf("Expected the copy to stop after 1 file, got %d", files)
+		}
+	})
+}
+
+func TestPosixPath_CopyFilter(t *testing.T) {
+	src := NewPosixPath(`./file/dir`)
+	dst := NewPosixPath(`./file/filter_copy`)
+	defer func() {
+		_ = dst.Remove()
+	}()
+	t.Run("exclude and filter", func(t *testing.T) {
+		err := src.Copy(dst, CopyOptions{Exclude: []string{"sub"}, Filter: func(src IPath, info fs.FileInfo) bool {
+			return src.Name() != "b.md"
+		}})
+		if err != nil {
+			t.Fatalf("Failed to copy directory: %v", err)
+		}
+		if !dst.Join("a.md").Exists() || dst.Join("b.md").Exists(false) || dst.Join("sub").Exists(false) {
+			t.Errorf("Expected only a.md to be copied")
+		}
+	})
+	t.Run("include", func(t *testing.T) {
+		var last CopyProgress
+		err := src.Copy(dst, CopyOptions{Replace: true, Include: []string{"x.md"}, Progress: func(progress CopyProgress) {
+			last = progress
+		}})
+		if err != nil {
+			t.Fatalf("Failed to copy directory: %v", err)
+		}
+		if !dst.Join("sub", "x.md").Exists() || dst.Join("a.md").Exists(false) || dst.Join("sub", "y.md").Exists(false) {
+			t.Errorf("Expected only sub/x.md to be copied")
+		}
+		if last.TotalFiles != 1 || last.Files != 1 {
+			t.Errorf("Expected 1 file to be copied, got %+v", last)
+		}
+	})
+}
+
+func TestPosixPath_CopyMerge(t *testing.T) {
+	src := NewPosixPath(`./file/dir`)
+	dst := NewPosixPath(`./file/merge_dst`)
+	defer func() {
+		_ = dst.Remove()
+	}()
+	// 目标中已经存在的目录会递归合并，即使使用 MergeModeError
+	if err := dst.Join("sub").Mkdir(); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := src.CopyMerge(dst); err != nil {
+		t.Fatalf("Failed to merge directories: %v", err)
+	}
+	if !dst.Join("a.md").Exists() || !dst.Join("sub", "y.md").Exists() {
+		t.Errorf("Expected all files to be merged")
+	}
+
+	t.Run("newer and larger", func(t *testing.T) {
+		// a.md 的内容更少但是更新，b.md 的内容更少并且更旧
+		future := time.Now().Add(time.Hour)
+		err := errors.Join(dst.Join("a.md").Write("new"), dst.Join("b.md").Write(""),
+			os.Chtimes(dst.Join("a.md").String(), time.Time{}, future))
+		if err != nil {
+			t.Fatalf("Failed to prepare target files: %v", err)
+		}
+		if err := src.CopyMerge(dst, CopyOptions{MergeMode: MergeModeNewer}); err != nil {
+			t.Fatalf("Failed to merge directories: %v", err)
+		}
+		if dst.Join("a.md").MustRead() != "new" {
+			t.Errorf("Expected the newer target file to be kept")
+		}
+		if err := src.CopyMerge(dst, CopyOptions{MergeMode: MergeModeLarger}); err != nil {
+			t.Fatalf("Failed to merge directories: %v", err)
+		}
+		if dst.Join("b.md").MustRead() != "b.md content" {
+			t.Errorf("Expected the smaller target file to be replaced")
+		}
+	})
+	t.Run("keep both", func(t *testing.T) {
+		if err := src.CopyMerge(dst, CopyOptions{MergeMode: MergeModeKeepBoth}); err != nil {
+			t.Fatalf("Failed to merge directories: %v", err)
+		}
+		if !dst.Join("a (1).md").Exists() || !dst.Join("sub", "x (1).md").Exists() {
+			t.Errorf("Expected incoming files to be renamed")
+		}
+	})
+	t.Run("callback", func(t *testing.T) {
+		var conflicts []string
+		err := src.CopyMerge(dst, CopyOptions{MergeMode: MergeModeCallback,
+			OnConflict: func(src, dst IPath) (MergeAction, error) {
+				conflicts = append(conflicts, dst.String())
+				if src.Name() == "a.md" {
+					return MergeActionReplace, nil
+				}
+				return MergeActionSkip, nil
+			}})
+		if err != nil {
+			t.Fatalf("Failed to merge directories: %v", err)
+		}
+		if dst.Join("a.md").MustRead() != "a.md content" {
+			t.Errorf("Expected a.md to be replaced")
+		}
+		if !slices.Contains(conflicts, `file/merge_dst/sub/x.md`) {
+			t.Errorf("Expected conflicts in sub directory, got %v", conflicts)
+		}
+	})
+}
+
+func TestPosixPath_MoveMerge(t *testing.T) {
+	src := NewPosixPath(`./file/move_src`)
+	dst := NewPosixPath(`./file/move_dst`)
+	defer func() {
+		_ = src.Remove()
+		_ = dst.Remove()
+	}()
+	mtime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	prepare := func(t *testing.T) {
+		err := errors.Join(src.Join("a.md").Write("new"), src.Join("sub", "b.md").Write("b"),
+			dst.Join("a.md").Write("old"), os.Chtimes(src.Join("a.md").String(), time.Time{}, mtime))
+		if err != nil {
+			t.Fatalf("Failed to prepare files: %v", err)
+		}
+	}
+	t.Run("merge mode", func(t *testing.T) {
+		prepare(t)
+		if err := src.MoveMerge(dst, CopyOptions{MergeMode: MergeModeReplace}); err != nil {
+			t.Fatalf("Failed to move directories: %v", err)
+		}
+		if src.Exists(false) || dst.Join("a.md").MustRead() != "new" || !dst.Join("sub", "b.md").Exists() {
+			t.Errorf("Expected all files to be moved into the target")
+		}
+		// 没有要求保留修改时间
+		info, err := dst.Join("a.md").Lstat()
+		if err != nil {
+			t.Fatalf("Failed to read file status: %v", err)
+		}
+		if info.ModTime().Equal(mtime) {
+			t.Errorf("Expected the modification time not to be preserved, got %v", info.ModTime())
+		}
+	})
+	t.Run("preserve times", func(t *testing.T) {
+		prepare(t)
+		err := src.MoveMerge(dst, CopyOptions{MergeMode: MergeModeReplace, PreserveTimes: true})
+		if err != nil {
+			t.Fatalf("Failed to move directories: %v", err)
+		}
+		info, err := dst.Join("a.md").Lstat()
+		if err != nil {
+			t.Fatalf("Failed to read file status: %v", err)
+		}
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("Expected the modification time to be preserved, got %v", info.ModTime())
+		}
+	})
+	t.Run("filter", func(t *testing.T) {
+		prepare(t)
+		err := src.MoveMerge(dst, CopyOptions{MergeMode: MergeModeReplace, Exclude: []string{"sub"}})
+		if !errors.Is(err, ErrMove) {
+			t.Fatalf("Expected ErrMove, got %v", err)
+		}
+		// 被排除的文件仍然在源目录中
+		if !src.Join("sub", "b.md").Exists() || !src.Join("a.md").Exists() {
+			t.Errorf("Expected the source directory to be kept")
+		}
+	})
+}
+
+func TestPosixPath_CopyPreserve(t *testing.T) {
+	src := NewPosixPath(`./file/preserve`)
+	srcFile := src.Join("run.sh")
+	if err := srcFile.Write("echo ok"); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	defer func() {
+		_ = src.Remove()
+	}()
+	if err := os.Chmod(srcFile.String(), 0o750); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+	mtime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, path := range []IPath{srcFile, src} {
+		if err := os.Chtimes(path.String(), time.Time{}, mtime); err != nil {
+			t.Fatalf("Failed to change times: %v", err)
+		}
+	}
+
+	dst := NewPosixPath(`./file/preserve_copy`)
+	defer func() {
+		_ = dst.Remove()
+	}()
+	if err := src.Copy(dst, CopyOptions{PreserveMode: true, PreserveTimes: true}); err != nil {
+		t.Fatalf("Failed to copy directory: %v", err)
+	}
+	for _, path := range []IPath{dst.Join("run.sh"), dst} {
+		info, err := path.Lstat()
+		if err != nil {
+			t.Fatalf("Failed to stat %q: %v", path, err)
+		}
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("Expected modification time of %q to be %v, got %v", path, mtime, info.ModTime())
+		}
+	}
+	info, err := dst.Join("run.sh").Lstat()
+	if err != nil {
+		t.Fatalf("Failed to stat copied file: %v", err)
+	}
+	if info.Mode().Perm() != 0o750 {
+		t.Errorf("Expected mode 0750, got %v", info.Mode().Perm())
+	}
+
+	// 默认不保留元数据，目标已经存在时可以替换
+	if err := src.Copy(dst, CopyOptions{Replace: true}); err != nil {
+		t.Fatalf("Failed to copy directory: %v", err)
+	}
+	info, err = dst.Join("run.sh").Lstat()
+	if err != nil {
+		t.Fatalf("Failed to stat copied file: %v", err)
+	}
+	if info.ModTime().Equal(mtime) {
+		t.Errorf("Expected modification time not to be preserved")
+	}
+}
+
+func TestPosixPath_Glob(t *testing.T) {
+	// 创建一个指向上级目录的符号链接
+	err := NewPosixPath("./file/link-to-parent").Symlink(NewPosixPath("."), true)
+	if err != nil {
+		t.Fatalf("Failed to create symlink ./file/link-to-parent : %v", err)
+	}
+	t.Run("Glob without follow symlinks", func(t *testing.T) {
+		p := NewPosixPath(`./file/dir`)
+		matches := p.MustGlob("**/*.md")
+		t.Logf("Matched files: %v", matches)
+		matchesStr := make([]string, len(matches))
+		for i, match := range matches {
+			matchesStr[i] = match.String()
+		}
+		if !slices.Contains(matchesStr, "file/dir/sub/y.md") {
+			t.Errorf("Expected to find file 'file/dir/sub/y.md' in matches, but it was not found")
+		}
+	})
+	t.Run("Glob with follow symlinks", func(t *testing.T) {
+		// 创建一个指向上级目录的符号链接，形成循环
+		loop := NewPosixPath("./file/dir/sub/loop")
+		if err := loop.Symlink(NewPosixPath(".."), true); err != nil {
+			t.Fatalf("Failed to create symlink %q: %v", loop, err)
+		}
+		defer loop.Remove()
+		matches := NewPosixPath(`./file/dir`).MustGlob("**/*.md", GlobOptions{Follow: true, SkipOnCycle: true})
+		t.Logf("Matched files: %v", matches)
+		matchesStr := make([]string, len(matches))
+		for i, match := range matches {
+			matchesStr[i] = match.String()
+		}
+		if len(matches) != 4 || !slices.Contains(matchesStr, "file/dir/sub/y.md") {
+			t.Errorf("Expected each markdown file exactly once, got %v", matchesStr)
+		}
+	})
+	t.Run("Glob through a followed link", func(t *testing.T) {
+		// 结果位于链接下面，而不是链接解析后的绝对路径
+		p := NewPosixPath(`./file`)
+		for _, relative := range []bool{false, true} {
+			matches := p.MustGlob("ldir/*.md", GlobOptions{Follow: true, Relative: relative, Sort: true})
+			matchesStr := make([]string, len(matches))
+			for i, match := range matches {
+				matchesStr[i] = match.String()
+			}
+			expected := []string{"file/ldir/a.md", "file/ldir/b.md"}
+			if relative {
+				expected = []string{"ldir/a.md", "ldir/b.md"}
+			}
+			if !slices.Equal(matchesStr, expected) {
+				t.Errorf("Expected %v, got %v", expected, matchesStr)
+			}
+		}
+	})
+	t.Run("Glob with compiled pattern", func(t *testing.T) {
+		pattern := purepath.MustCompilePatternWithFlavour(NewPosixPath(".").Flavour(), "**/*.{md,txt}")
+		matches := NewPosixPath(`./file/dir`).MustGlobPattern(pattern)
+		t.Logf("Matched files: %v", matches)
+		matchesStr := make([]string, len(matches))
+		for i, match := range matches {
+			matchesStr[i] = match.String()
+		}
+		if !slices.Contains(matchesStr, "file/dir/sub/y.md") {
+			t.Errorf("Expected to find file 'file/dir/sub/y.md' in matches, but it was not found")
+		}
+	})
+	t.Run("Glob only reads reachable directories", func(t *testing.T) {
+		// 不会进入 link-to-parent，所以跟随符号链接时也不会检测到循环
+		matches := NewPosixPath(".").MustGlob("file/dir/*.md", GlobOptions{Follow: true})
+		matchesStr := make([]string, len(matches))
+		for i, match := range matches {
+			matchesStr[i] = match.String()
+		}
+		slices.Sort(matchesStr)
+		if !slices.Equal(matchesStr, []string{"file/dir/a.md", "file/dir/b.md"}) {
+			t.Errorf("Expected only the markdown files in 'file/dir', got %v", matchesStr)
+		}
+	})
+	t.Run("Glob relative to receiver", func(t *testing.T) {
+		p := NewPosixPath(`./file/dir`)
+		testcases := []struct {
+			matches []IPath
+			output  []string
+		}{
+			{p.MustGlob("*.md"), []string{"file/dir/a.md", "file/dir/b.md"}},
+			{p.MustRGlob("y.md"), []string{"file/dir/sub/y.md"}},
+			{p.MustGlob("sub/*.md", GlobOptions{Relative: true}), []string{"sub/x.md", "sub/y.md"}},
+		}
+		for _, tc := range testcases {
+			matchesStr := make([]string, len(tc.matches))
+			for i, match := range tc.matches {
+				matchesStr[i] = match.String()
+			}
+			slices.Sort(matchesStr)
+			if !slices.Equal(matchesStr, tc.output) {
+				t.Errorf("Expected %v, got %v", tc.output, matchesStr)
+			}
+		}
+		if _, err := p.Glob("/*.md"); !errors.Is(err, ErrGlobPattern) {
+			t.Errorf("Expected ErrGlobPattern for anchored pattern, got %v", err)
+		}
+	})
+	t.Run("Glob with options", func(t *testing.T) {
+		p := NewPosixPath(`./file/dir`)
+		if err := p.Join(".hidden.md").Write("hidden"); err != nil {
+			t.Fatalf("Failed to create hidden file: %v", err)
+		}
+		defer p.Join(".hidden.md").Remove()
+		if err := p.Join(".hidden", "z.md").Write("hidden"); err != nil {
+			t.Fatalf("Failed to create file in hidden directory: %v", err)
+		}
+		defer p.Join(".hidden").Remove()
+		no := false
+		testcases := []struct {
+			name    string
+			pattern string
+			options GlobOptions
+			output  []string
+		}{
+			{"only files", "**/*", GlobOptions{OnlyFiles: true}, []string{"a.md", "b.md", "sub/x.md", "sub/y.md"}},
+			{"only dirs", "**/*", GlobOptions{OnlyDirs: true}, []string{"sub"}},
+			{"max depth", "**/*.md", GlobOptions{MaxDepth: 1}, []string{"a.md", "b.md"}},
+			{"exclude", "**/*.md", GlobOptions{Exclude: []string{"sub", "b.*"}}, []string{"a.md"}},
+			{"include hidden", "*.md", GlobOptions{IncludeHidden: true}, []string{".hidden.md", "a.md", "b.md"}},
+			{"case sensitive", "*.MD", GlobOptions{CaseSensitive: &no}, []string{"a.md", "b.md"}},
+			{"literal hidden file", ".hidden.md", GlobOptions{}, []string{".hidden.md"}},
+			{"literal hidden dir", ".hidden/*", GlobOptions{}, []string{".hidden/z.md"}},
+			{"hidden dir by wildcard", "*/*.md", GlobOptions{}, []string{"sub/x.md", "sub/y.md"}},
+			{"case insensitive literal", "SUB/X.MD", GlobOptions{CaseSensitive: &no}, []string{"sub/x.md"}},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				tc.options.Relative, tc.options.Sort = true, true
+				matches := p.MustGlob(tc.pattern, tc.options)
+				var matchesStr []string
+				for _, match := range matches {
+					matchesStr = append(matchesStr, match.String())
+				}
+				if !slices.Equal(matchesStr, tc.output) {
+					t.Errorf("Expected %v, got %v", tc.output, matchesStr)
+				}
+			})
+		}
+		if _, err := p.Glob("*", GlobOptions{OnlyFiles: true, OnlyDirs: true}); !errors.Is(err, ErrGlobOptions) {
+			t.Errorf("Expected ErrGlobOptions, got %v", err)
+		}
+	})
+	t.Run("Glob with ignore", func(t *testing.T) {
+		ignore := MustNewIgnoreMatcher("", "sub/", "b.md")
+		matches := NewPosixPath(`./file/dir`).MustGlob("**/*.md", GlobOptions{Ignore: ignore})
+		t.Logf("Matched files: %v", matches)
+		matchesStr := make([]string, len(matches))
+		for i, match := range matches {
+			matchesStr[i] = match.String()
+		}
+		if !slices.Equal(matchesStr, []string{"file/dir/a.md"}) {
+			t.Errorf("Expected only 'file/dir/a.md' in matches, got %v", matchesStr)
+		}
+	})
+}
+
+func TestPosixPath_Walk(t *testing.T) {
+	p := NewPosixPath(`./file`)
+	t.Run("Walk without follow symlinks", func(t *testing.T) {
+		err := p.Walk(func(path IPath, err error) error {
+			t.Logf("Visited path: %s", path.String())
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Failed to walk path: %v", err)
+		}
+	})
+	t.Run("Walk with follow symlinks", func(t *testing.T) {
+		// 创建一个指向上级目录的符号链接
+		err := NewPosixPath("./file/parent_link").Symlink(NewPosixPath("."), true)
+		if err != nil {
+			t.Fatalf("Failed to create symlink ./file/parent_link :\n\t %v", err)
+		}
+		err = p.Walk(func(path IPath, err error) error {
+			if err != nil { // 会出现读取链接失败的错误，目标不存在
+				t.Logf("Error walking path %s:\n\t %v", path.String(), err)
+				return nil // 继续遍历
+			}
+			t.Logf("Visited path: %s", path.String())
+			if path.Name() == "sub" {
+				t.Logf("Skipping sub directory: %s", path.String())
+				return WalkSkip // 跳过子目录的向下遍历
+			}
+			return err
+		}, WalkOptions{Follow: true})
+		if err != nil {
+			t.Fatalf("Failed to walk path: %v", err)
+		}
+	})
+	t.Run("Walk with stop", func(t *testing.T) {
+		err := p.Walk(func(path IPath, err error) error {
+			if err != nil { // 会出现读取链接失败的错误，目标不存在
+				t.Log(common.WrapMsg(err, "error walking path: %q", path))
+				return nil // 继续遍历
+			}
+			t.Logf("Visited path: %q", path)
+			if path.Name() == "sub" {
+				t.Logf("Stopping walk at sub directory: %q", path)
+				return WalkStop
+			}
+			return err
+		}, WalkOptions{Follow: true})
+		if !errors.Is(err, WalkStop) {
+			t.Fatalf("Failed to walk path: %v", err)
+		}
+	})
+	t.Run("Walk with depth and sort", func(t *testing.T) {
+		collect := func(options WalkOptions) []string {
+			var result []string
+			err := NewPosixPath(`./file/dir`).Walk(func(path IPath, err error) error {
+				result = append(result, path.String())
+				return err
+			}, options)
+			if err != nil {
+				t.Fatalf("Failed to walk path: %v", err)
+			}
+			return result
+		}
+		expected := []string{`file/dir/a.md`, `file/dir/b.md`, `file/dir/sub`}
+		if result := collect(WalkOptions{MinDepth: 1, MaxDepth: 1, Sort: true}); !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+		reverse := func(a, b IPath) int { return b.Compare(a) }
+		expected = []string{`file/dir/sub`, `file/dir/sub/y.md`, `file/dir/sub/x.md`, `file/dir/b.md`, `file/dir/a.md`}
+		if result := collect(WalkOptions{MinDepth: 1, SortFunc: reverse}); !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+	})
+	t.Run("Walk with OnError", func(t *testing.T) {
+		var skipped []string
+		err := p.Walk(func(path IPath, err error) error {
+			if err != nil {
+				t.Errorf("Error should not be passed to fn: %v", err)
+			}
+			return err
+		}, WalkOptions{Follow: true, OnError: func(path IPath, err error) WalkErrorAction {
+			skipped = append(skipped, path.Name())
+			return WalkErrorSkip // 跳过不存在的链接目标和重复访问的目录
+		}})
+		if err != nil {
+			t.Fatalf("Failed to walk path: %v", err)
+		}
+		if !slices.Contains(skipped, "lnodir") || !slices.Contains(skipped, "ldir") {
+			t.Errorf("Expected lnodir and ldir to be skipped, got %v", skipped)
+		}
+		err = p.Walk(func(path IPath, err error) error {
+			return err
+		}, WalkOptions{Follow: true, OnError: func(path IPath, err error) WalkErrorAction {
+			return WalkErrorAbort
+		}})
+		if err == nil {
+			t.Errorf("Expected the walk to be aborted")
+		}
+	})
+}
+
+func TestPosixPath_Iter(t *testing.T) {
+	p := NewPosixPath(`./file/dir`)
+	collect := func(seq iter.Seq2[IPath, error]) []string {
+		var result []string
+		for path, err := range seq {
+			if err != nil {
+				t.Fatalf("Failed to iterate: %v", err)
+			}
+			result = append(result, path.String())
+		}
+		slices.Sort(result)
+		return result
+	}
+	t.Run("IterDir", func(t *testing.T) {
+		expected := []string{"file/dir/a.md", "file/dir/b.md", "file/dir/sub"}
+		if result := collect(p.IterDir()); !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+	})
+	t.Run("IterWalk with prune", func(t *testing.T) {
+		prune := func(dir IPath) bool { return dir.Name() == "sub" }
+		expected := []string{"file/dir", "file/dir/a.md", "file/dir/b.md", "file/dir/sub"}
+		if result := collect(p.IterWalk(WalkOptions{Prune: prune})); !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+	})
+	t.Run("IterWalk with break", func(t *testing.T) {
+		count := 0
+		for range p.IterWalk() {
+			count++
+			break
+		}
+		if count != 1 {
+			t.Errorf("Expected the walk to stop after 1 path, got %d", count)
+		}
+	})
+	t.Run("IterGlob", func(t *testing.T) {
+		expected := []string{"file/dir/sub/x.md", "file/dir/sub/y.md"}
+		if result := collect(p.IterGlob("sub/*.md")); !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+		for path, err := range p.IterGlob("[") {
+			if !errors.Is(err, purepath.ErrInvalidPattern) {
+				t.Errorf("Expected ErrInvalidPattern for %q, got %v", path, err)
+			}
+		}
+	})
+}
+
+func TestPosixPath_WalkDirs(t *testing.T) {
+	p := NewPosixPath(`./file/dir`)
+	t.Run("top down with prune", func(t *testing.T) {
+		var dirs []string
+		for entry, err := range p.WalkDirs() {
+			if err != nil {
+				t.Fatalf("Failed to walk dirs: %v", err)
+			}
+			dirs = append(dirs, entry.Dir.String())
+			if !slices.Equal(entry.DirNames, []string{"sub"}) || !slices.Equal(entry.FileNames, []string{"a.md", "b.md"}) {
+				t.Errorf("Unexpected entry: %+v", entry)
+			}
+			entry.DirNames = nil // 不进入子目录
+		}
+		if !slices.Equal(dirs, []string{"file/dir"}) {
+			t.Errorf("Expected only 'file/dir', got %v", dirs)
+		}
+	})
+	t.Run("bottom up", func(t *testing.T) {
+		var dirs []string
+		for entry, err := range p.WalkDirs(WalkDirsOptions{BottomUp: true}) {
+			if err != nil {
+				t.Fatalf("Failed to walk dirs: %v", err)
+			}
+			dirs = append(dirs, entry.Dir.String())
+		}
+		if !slices.Equal(dirs, []string{"file/dir/sub", "file/dir"}) {
+			t.Errorf("Expected sub directory before its parent, got %v", dirs)
+		}
+	})
+	t.Run("follow symlinks", func(t *testing.T) {
+		var chain []string
+		for entry, err := range NewPosixPath(`./file`).WalkDirs(WalkDirsOptions{Follow: true}) {
+			var cycleErr *WalkCycleError
+			if entry.Dir.Name() == "ldir" && errors.As(err, &cycleErr) {
+				for _, path := range cycleErr.Chain {
+					chain = append(chain, path.String())
+				}
+			}
+		}
+		// ldir 指向已经访问过的 dir，不会重复访问
+		if !slices.Equal(chain, []string{"file/dir", "file/ldir"}) {
+			t.Errorf("Expected ldir to be reported as a revisit of dir, got %v", chain)
+		}
+	})
+
+
+}
+
+func TestPosixPath_ReadDirEntries(t *testing.T) {
+	entries := NewPosixPath(`./file`).MustReadDir()
+	byName := make(map[string]*Entry)
+	for _, path := range entries {
+		entry, ok := path.(*Entry)
+		if !ok {
+			t.Fatalf("Expected *Entry, got %T", path)
+		}
+		byName[entry.Name()] = entry
+	}
+	testcases := []struct {
+		name     string
+		isLink   bool
+		isDir    bool // 不跟随符号链接
+		isDirF   bool // 跟随符号链接
+		exists   bool // 跟随符号链接
+		typeMode fs.FileMode
+	}{
+		{"f.md", false, false, false, true, 0},
+		{"dir", false, true, true, true, fs.ModeDir},
+		{"ldir", true, false, true, true, fs.ModeSymlink},
+		{"lnodir", true, false, false, false, fs.ModeSymlink},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := byName[tc.name]
+			if entry == nil {
+				t.Fatalf("Entry %q not found", tc.name)
+			}
+			if entry.IsLink() != tc.isLink || entry.IsDir(false) != tc.isDir || entry.IsDir() != tc.isDirF ||
+				entry.Exists() != tc.exists || !entry.Exists(false) || entry.Type() != tc.typeMode {
+				t.Errorf("Unexpected entry state for %q", tc.name)
+			}
+			info, err := entry.Info()
+			if err != nil || info.Name() != tc.name {
+				t.Errorf("Failed to get info of %q: %v", tc.name, err)
+			}
+		})
+	}
+}
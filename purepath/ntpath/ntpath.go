@@ -3,19 +3,23 @@ package ntpath
 import (
 	"errors"
 	"fmt"
-	"path/filepath"
+	pathpkg "path"
 	"regexp"
 	"strings"
 
 	"github.com/viocha/go-pathlib/internal/common"
+	px "github.com/viocha/go-pathlib/purepath/posixpath"
 )
 
 var (
-	ErrNoName        = errors.New("no name provided")
-	ErrNotRelative   = errors.New("path is not relative to the other path")
-	ErrInvalidName   = errors.New("invalid name provided")
-	ErrInvalidAnchor = errors.New("invalid anchor provided")
-	ErrInvalidSuffix = errors.New("invalid suffix provided")
+	ErrNoName        = common.ErrNoName
+	ErrNotRelative   = common.ErrNotRelative
+	ErrInvalidName   = common.ErrInvalidName
+	ErrInvalidAnchor = common.ErrInvalidAnchor
+	ErrInvalidSuffix = common.ErrInvalidSuffix
+	ErrMountPath     = errors.New("failed to convert mount path")
+	ErrExtendedPath  = errors.New("failed to convert extended-length path")
+	ErrInvalidStream = errors.New("invalid stream")
 )
 
 var (
@@ -26,87 +30,110 @@ var (
 )
 
 var (
-	RegUNC       = regexp.MustCompile(`^\\\\[^\\]+\\[^\\]+\\`)
-	RegDrive     = regexp.MustCompile(`(?i)^[A-Z]:\\?`)
-	RegDriveRoot = regexp.MustCompile(`(?i)^[A-Z]:\\`) // 带有盘符和反斜杠的根路径
+	RegUNC        = regexp.MustCompile(`^\\\\[^\\]+\\[^\\]+\\`)
+	RegUNCDevice  = regexp.MustCompile(`(?i)^\\\\\?\\UNC\\[^\\]+\\[^\\]+\\`) // 扩展长度的UNC路径 \\?\UNC\server\share\
+	RegDrive      = regexp.MustCompile(`(?i)^[A-Z]:\\?`)
+	RegDriveRoot  = regexp.MustCompile(`(?i)^[A-Z]:\\`)   // 带有盘符和反斜杠的根路径
+	RegStreamType = regexp.MustCompile(`(?i)^\$[A-Z_]+$`) // NTFS数据流类型，如 $DATA
 )
 
+// SplitDrive 将路径拆分为驱动器部分和剩余部分，驱动器可以是盘符 c: 或UNC共享名 \\server\share，
+// 也可以是设备路径 \\?\C:、\\?\UNC\server\share、\\.\PhysicalDrive0、\\?\Volume{GUID}，
+// 只使用字符串处理，不依赖当前操作系统，/ 和 \ 都会被视为路径分隔符并统一转换为 \
+func SplitDrive(path string) (drive, rest string) {
+	path = strings.ReplaceAll(path, "/", `\`)
+
+	// 带盘符路径
+	if len(path) >= 2 && path[1] == ':' && isASCIILetter(path[0]) {
+		return path[:2], path[2:]
+	}
+
+	// UNC路径，服务器名不能为空，共享名缺失时整个 \\server 视为驱动器。
+	// 设备路径 \\?\X 和 \\.\X 可以看成服务器名为 ? 或 . 的UNC路径，\\?\UNC\ 之后才是真正的服务器名
+	if strings.HasPrefix(path, `\\`) && len(path) > 2 && path[2] != '\\' {
+		start := 2
+		if hasUNCDevicePrefix(path) {
+			start = len(uncDevicePrefix)
+		}
+		serverEnd := strings.Index(path[start:], `\`)
+		if serverEnd < 0 { // 只有服务器名
+			return path, ""
+		}
+		serverEnd += start
+		shareLen := strings.Index(path[serverEnd+1:], `\`)
+		if shareLen < 0 {
+			shareLen = len(path) - serverEnd - 1
+		}
+		if shareLen == 0 { // 共享名为空
+			return path[:serverEnd], path[serverEnd:]
+		}
+		driveEnd := serverEnd + 1 + shareLen
+		return path[:driveEnd], path[driveEnd:]
+	}
+	return "", path
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// 是否为UNC驱动器，UNC路径总是带有根路径，设备路径也被视为UNC驱动器
+func isUNCDrive(drive string) bool {
+	return strings.HasPrefix(drive, `\\`)
+}
+
+const (
+	extendedPrefix  = `\\?\`     // 扩展长度路径前缀，Windows不会对其后的路径做规范化
+	devicePrefix    = `\\.\`     // DOS设备路径前缀
+	uncDevicePrefix = `\\?\UNC\` // 扩展长度的UNC路径前缀
+)
+
+func hasUNCDevicePrefix(path string) bool {
+	return len(path) >= len(uncDevicePrefix) && strings.EqualFold(path[:len(uncDevicePrefix)], uncDevicePrefix)
+}
+
+// IsDevicePath 返回路径是否以 \\?\ 或 \\.\ 开头，包括扩展长度路径和DOS设备路径
+func IsDevicePath(path string) bool {
+	path = strings.ReplaceAll(path, "/", `\`)
+	return strings.HasPrefix(path, extendedPrefix) || strings.HasPrefix(path, devicePrefix)
+}
+
 // Parts 返回路径的各个部分，包括anchor部分，以及剩余的目录和名称部分。
 // 会假定anchor的名称合法，然后使用路径分隔符分割得到目录和名称部分
 func Parts(path string) []string {
+	path = Clean(path) // 先清理路径，确保是标准格式
 	if path == "." {
 		return nil
 	}
-	path = Clean(path) // 先清理路径，确保是标准格式
 
-	// UNC路径
-	if strings.HasPrefix(path, `\\`) {
-		// 提取UNC路径的anchor部分
-		anchor := RegUNC.FindString(path)
-		if len(path) == len(anchor) { // 如果只有根路径
-			return []string{anchor}
-		}
-		// 去掉根部分后进行分割
-		parts := strings.Split(path[len(anchor):], `\`)
-		return append([]string{anchor}, parts...)
+	drive, rest := SplitDrive(path)
+	anchor := drive
+	if strings.HasPrefix(rest, `\`) { // anchor包含根路径
+		anchor += `\`
+		rest = rest[1:]
 	}
-
-	// 带盘符路径
-	if RegDrive.MatchString(path) {
-		// 提取盘符和冒号，以及可选的反斜杠
-		anchor := RegDrive.FindString(path)
-		var parts []string
-		if len(path) == len(anchor) { // 如果只有盘符
-			return []string{anchor}
-		}
-		parts = strings.Split(path[len(anchor):], `\`)
-		return append([]string{anchor}, parts...)
+	if rest == "" { // 如果只有根路径
+		return []string{anchor}
 	}
-
-	// 以反斜杠开头的路径
-	if strings.HasPrefix(path, `\`) {
-		if len(path) == 1 {
-			return []string{`\`} // 只有根路径
-		}
-		// 去掉开头的反斜杠，并进行分割
-		parts := strings.Split(path[1:], `\`)
-		return append([]string{`\`}, parts...)
+	parts := strings.Split(rest, `\`)
+	if anchor == "" {
+		return parts
 	}
-
-	// 直接分割
-	return strings.Split(path, `\`)
+	return append([]string{anchor}, parts...)
 }
 
+// 返回盘符 c: 或UNC共享名 \\server\share，其他情况返回空字符串
 func Drive(path string) string {
-	parts := Parts(path)
-	if len(parts) == 0 {
-		return ""
-	}
-	first := parts[0]
-	if strings.HasPrefix(first, `\\`) { // UNC路径
-		return strings.TrimSuffix(first, `\`)
-	}
-	if len(first) >= 2 && first[1] == ':' { // 驱动器盘符
-		return strings.TrimSuffix(first, `\`)
-	}
-	return ""
+	drive, _ := SplitDrive(Clean(path))
+	return drive
 }
 
+// 返回根路径标识符，UNC路径、带盘符的绝对路径、以\开头的路径的根为 `\`，其他情况返回空字符串
 func Root(path string) string {
-	parts := Parts(path)
-	if len(parts) == 0 {
-		return ""
-	}
-
-	first := parts[0]
-	if strings.HasPrefix(first, `\\`) { // UNC路径
-		return `\`
-	} else if len(first) >= 2 && first[1] == ':' && strings.HasSuffix(first, `\`) { // 带盘符且以\结尾
-		return `\`
-	} else if first == `\` { // 以\开头的路径
+	_, rest := SplitDrive(Clean(path))
+	if strings.HasPrefix(rest, `\`) {
 		return `\`
 	}
-
 	return ""
 }
 
@@ -152,12 +179,41 @@ func ValidateName(name string) error {
 	return nil
 }
 
+// SplitStream 将名称拆分为文件名和NTFS备用数据流部分，如 report.docx:Zone.Identifier:$DATA
+// 拆分为 report.docx 和 Zone.Identifier:$DATA，没有数据流时stream为空字符串
+func SplitStream(name string) (base, stream string) {
+	base, stream, _ = strings.Cut(name, ":")
+	return base, stream
+}
+
+// 检查NTFS备用数据流是否合法，格式为 name[:type]，空字符串表示没有数据流。
+// 数据流名称不能包含 \ / : 和控制字符，类型必须以 $ 开头，如 $DATA，省略名称时表示默认数据流，如 :$DATA
+func ValidateStream(stream string) error {
+	if stream == "" {
+		return nil
+	}
+	name, streamType, hasType := strings.Cut(stream, ":")
+	if name == "" && !hasType {
+		return common.WrapMsg(ErrInvalidStream, "stream name cannot be empty")
+	}
+	if len(name) > 255 {
+		return common.WrapMsg(ErrInvalidStream, "stream %q exceeds maximum length of 255 characters", stream)
+	}
+	if strings.ContainsFunc(name, func(r rune) bool { return r < 0x20 || r == '\\' || r == '/' }) {
+		return common.WrapMsg(ErrInvalidStream, "stream %q contains invalid character", stream)
+	}
+	if hasType && !RegStreamType.MatchString(streamType) {
+		return common.WrapMsg(ErrInvalidStream, "stream %q has invalid type %q", stream, streamType)
+	}
+	return nil
+}
+
 func ValidateAnchor(anchor string) error {
 	// 替换路径中的斜杠
 	anchor = strings.ReplaceAll(anchor, `/`, `\`)
 
-	if strings.HasPrefix(anchor, `\\`) { // UNC路径
-		if !RegUNC.MatchString(anchor) {
+	if strings.HasPrefix(anchor, `\\`) { // UNC路径或设备路径
+		if !RegUNC.MatchString(anchor) || hasUNCDevicePrefix(anchor) && !RegUNCDevice.MatchString(anchor) {
 			return common.WrapMsg(ErrInvalidAnchor, "invalid UNC anchor %q", anchor)
 		}
 	} else if strings.Contains(anchor, ":") { // 驱动器盘符
@@ -182,8 +238,18 @@ func ValidatePath(path string) error {
 		parts = parts[1:] // 去掉anchor部分
 	}
 
-	// 检查每个部分的名称是否合法
-	for _, part := range parts {
+	// 检查每个部分的名称是否合法，只有最后一个部分可以带有数据流
+	for i, part := range parts {
+		if i == len(parts)-1 {
+			base, stream := SplitStream(part)
+			if base != part && stream == "" { // 以 : 结尾
+				return common.WrapMsg(ErrInvalidStream, "stream name cannot be empty in %q", part)
+			}
+			if err := ValidateStream(stream); err != nil {
+				return err
+			}
+			part = base
+		}
 		if err := ValidateName(part); err != nil {
 			return err
 		}
@@ -220,32 +286,84 @@ func ToValidName(name string) string {
 	return name
 }
 
-// 规范化路径，并不会确保名称合法
+// 规范化路径，并不会确保名称合法。只使用字符串处理，结果和当前操作系统无关：
+// 统一使用 \ 作为分隔符，去掉多余的分隔符和 . ，解析 .. ，UNC路径总是以 \ 结尾的根路径。
+// 扩展长度路径 \\?\ 中的 . 和 .. 会原样保留，因为Windows不会对其进行解析
 func Clean(path string) string {
-	path = filepath.Clean(path)
+	drive, rest := SplitDrive(path)
+	rooted := strings.HasPrefix(rest, `\`) || isUNCDrive(drive)
+	literal := strings.HasPrefix(drive, extendedPrefix)
+
+	var names []string
+	for _, name := range strings.Split(rest, `\`) {
+		if literal && name != "" {
+			names = append(names, name)
+			continue
+		}
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			if len(names) > 0 && names[len(names)-1] != ".." {
+				names = names[:len(names)-1] // 回退上一级
+			} else if !rooted {
+				names = append(names, "..") // 相对路径保留无法回退的 ..
+			} // 根路径的上一级还是根路径
+		default:
+			names = append(names, name)
+		}
+	}
 
-	// 替换为Windows反斜杠
-	path = strings.ReplaceAll(path, "/", `\`)
+	anchor := drive
+	if rooted {
+		anchor += `\`
+	}
+	path = anchor + strings.Join(names, `\`)
+	if path == "" {
+		return "."
+	}
+	return path
+}
 
-	// 确保UNC根路径以斜杠结尾
-	if strings.HasPrefix(path, `\\`) {
-		if strings.Count(path, `\`) == 3 && !strings.HasSuffix(path, `\`) { // 只有3个斜杠，则确保末尾有一个斜杠
-			path += `\`
+// 将other连接到path后面，规则和 NewPureWindowsPath 一致：
+// UNC路径和带盘符的绝对路径会覆盖前面的路径；盘符相同的相对路径会被附加，盘符不同则覆盖；
+// 以 \ 开头的路径会保留前面的驱动器
+func Join(path, other string) string {
+	path, other = Clean(path), Clean(other)
+	pathDrive, _ := SplitDrive(path)
+	drive, rest := SplitDrive(other)
+
+	switch {
+	case isUNCDrive(drive): // UNC绝对路径，覆盖前面的路径
+		return other
+	case drive != "": // 以盘符开头的路径
+		if strings.HasPrefix(rest, `\`) || !strings.EqualFold(drive, pathDrive) {
+			return other // 有根路径的绝对路径，或者盘符不同，直接覆盖
 		}
+		return joinRelative(path, rest) // 盘符相同的相对路径，去掉盘符后附加
+	case strings.HasPrefix(other, `\`): // 以\开头的路径，保留前面的驱动器
+		return Clean(pathDrive + other)
+	default: // 常规的相对路径，注意 .. 也会被解析，和python的不同
+		return joinRelative(path, other)
+	}
+}
+
+// 附加一个不带驱动器和根路径的相对路径
+func joinRelative(path, other string) string {
+	if other == "" || other == "." {
+		return path
 	}
-	// 移除盘符相对路径末尾的.号，防止出现 c:.
-	if len(path) >= 2 && path[1] == ':' && strings.HasSuffix(path, `.`) {
-		path = strings.TrimSuffix(path, `.`)
+	drive, rest := SplitDrive(path)
+	if (drive != "" && rest == "") || strings.HasSuffix(path, `\`) { // 盘符相对路径 c: 或者以分隔符结尾的anchor
+		return Clean(path + other)
 	}
-	return path
+	return Clean(path + `\` + other)
 }
 
 // 支持 ** 通配符的路径匹配函数，UNC也支持，UNC可以看成是第一个部分为空的以斜杠开头的路径
 func Match(pattern, path string) bool {
-	pattern = filepath.ToSlash(pattern)
-	path = filepath.ToSlash(path)
-	patternParts := strings.Split(pattern, "/")
-	pathParts := strings.Split(path, "/")
+	patternParts := strings.Split(strings.ReplaceAll(pattern, "/", `\`), `\`)
+	pathParts := strings.Split(strings.ReplaceAll(path, "/", `\`), `\`)
 
 	// 初始化记忆化缓存
 	memo := make(map[[2]int]bool)
@@ -275,11 +393,117 @@ func Match(pattern, path string) bool {
 		if pathIdx >= len(pathParts) {
 			return false
 		}
-		currentMatch, _ := filepath.Match(patternParts[patternIdx], pathParts[pathIdx])
-		if !currentMatch {
+		if !MatchName(patternParts[patternIdx], pathParts[pathIdx]) {
 			return false
 		}
 		return match(patternIdx+1, pathIdx+1)
 	}
 	return match(0, 0)
 }
+
+// 匹配单个名称，支持 * ? [] 通配符。名称中不包含分隔符，所以 \ 不会出现，
+// 使用 path.Match 可以在所有操作系统上得到相同的结果
+func MatchName(pattern, name string) bool {
+	matched, _ := pathpkg.Match(pattern, name)
+	return matched
+}
+
+// ToMountPath 将Windows路径转换成盘符挂载在 mountRoot 下的POSIX路径，
+// 如 mountRoot 为 /mnt 时 C:\Users 转换成 /mnt/c/Users，UNC路径转换成 //server/share 形式，相对路径只替换分隔符。
+// 带盘符的相对路径和不带盘符的根路径无法确定挂载位置，会返回错误
+func ToMountPath(path, mountRoot string) (string, error) {
+	root, err := cleanMountRoot(mountRoot)
+	if err != nil {
+		return "", err
+	}
+
+	drive, rest := SplitDrive(Clean(path))
+	rest = strings.ReplaceAll(rest, `\`, "/")
+	switch {
+	case IsDevicePath(drive):
+		return "", common.WrapMsg(ErrMountPath, "cannot convert device path %q", path)
+	case isUNCDrive(drive):
+		return px.Clean(strings.ReplaceAll(drive, `\`, "/") + rest), nil
+	case drive != "":
+		if !strings.HasPrefix(rest, "/") {
+			return "", common.WrapMsg(ErrMountPath, "cannot convert drive-relative path %q", path)
+		}
+		return px.Clean(root + "/" + strings.ToLower(drive[:1]) + rest), nil
+	case strings.HasPrefix(rest, "/"):
+		return "", common.WrapMsg(ErrMountPath, "cannot convert rooted path %q without drive", path)
+	default:
+		return rest, nil
+	}
+}
+
+// FromMountPath 将盘符挂载在 mountRoot 下的POSIX路径转换成Windows路径，是 ToMountPath 的逆操作，
+// 如 mountRoot 为 /mnt 时 /mnt/c/Users 转换成 C:\Users，盘符统一使用大写。
+// 以 // 开头的路径转换成UNC路径，相对路径只替换分隔符，不在 mountRoot 下的绝对路径会返回错误
+func FromMountPath(path, mountRoot string) (string, error) {
+	root, err := cleanMountRoot(mountRoot)
+	if err != nil {
+		return "", err
+	}
+
+	path = px.Clean(path)
+	if px.Root(path) != "/" { // UNC路径或者相对路径
+		return Clean(path), nil
+	}
+
+	rel, ok := path[1:], root == ""
+	if !ok {
+		rel, ok = strings.CutPrefix(path, root+"/")
+	}
+	letter, rest, _ := strings.Cut(rel, "/")
+	if !ok || len(letter) != 1 || !isASCIILetter(letter[0]) {
+		return "", common.WrapMsg(ErrMountPath, "path %q is not a drive under mount root %q", path, mountRoot)
+	}
+	return Clean(strings.ToUpper(letter) + `:\` + rest), nil
+}
+
+// 清理挂载根路径，必须是以单个 / 开头的POSIX绝对路径，返回的结果不包含末尾的 /，所以根目录 / 会返回空字符串
+func cleanMountRoot(mountRoot string) (string, error) {
+	root := px.Clean(mountRoot)
+	if px.Root(root) != "/" {
+		return "", common.WrapMsg(ErrMountPath, "mount root %q must be an absolute POSIX path", mountRoot)
+	}
+	return strings.TrimSuffix(root, "/"), nil
+}
+
+// ToExtendedLength 将绝对路径转换成扩展长度路径，从而突破 260 个字符的长度限制，
+// 如 C:\foo 转换成 \\?\C:\foo，\\server\share\foo 转换成 \\?\UNC\server\share\foo。
+// 路径会先被规范化，已经是设备路径时原样返回，相对路径无法转换，会返回错误
+func ToExtendedLength(path string) (string, error) {
+	path = Clean(path)
+	drive, rest := SplitDrive(path)
+	switch {
+	case IsDevicePath(drive):
+		return path, nil
+	case isUNCDrive(drive):
+		return uncDevicePrefix + path[2:], nil
+	case drive != "" && strings.HasPrefix(rest, `\`):
+		return extendedPrefix + path, nil
+	default:
+		return "", common.WrapMsg(ErrExtendedPath, "cannot convert relative path %q", path)
+	}
+}
+
+// FromExtendedLength 将扩展长度路径转换成普通路径，是 ToExtendedLength 的逆操作，
+// 如 \\?\C:\foo 转换成 C:\foo，\\?\UNC\server\share\foo 转换成 \\server\share\foo。
+// 不是扩展长度路径时原样返回，\\?\Volume{GUID} 这样没有对应普通路径的设备路径会返回错误
+func FromExtendedLength(path string) (string, error) {
+	path = Clean(path)
+	drive, _ := SplitDrive(path)
+	switch {
+	case !strings.HasPrefix(drive, extendedPrefix):
+		return path, nil
+	case hasUNCDevicePrefix(drive):
+		return Clean(`\\` + path[len(uncDevicePrefix):]), nil
+	}
+
+	inner := path[len(extendedPrefix):]
+	if innerDrive, _ := SplitDrive(inner); len(innerDrive) == 2 { // 盘符
+		return Clean(inner), nil
+	}
+	return "", common.WrapMsg(ErrExtendedPath, "device path %q has no equivalent normal path", path)
+}
@@ -0,0 +1,529 @@
+package purepath
+
+import (
+	"errors"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/viocha/go-pathlib/internal/common"
+	nt "github.com/viocha/go-pathlib/purepath/ntpath"
+	px "github.com/viocha/go-pathlib/purepath/posixpath"
+)
+
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+// PatternOptions 编译模式串的选项
+type PatternOptions struct {
+	CaseSensitive *bool // 是否区分大小写，nil表示使用风格的默认规则：Windows不区分，POSIX区分
+}
+
+// Pattern 编译后的通配符模式，可以重复用于匹配大量路径。支持的语法：
+//   - * 匹配名称中的任意字符，? 匹配单个字符
+//   - [abc] [a-z] 字符类，[!x] 和 [^x] 表示取反
+//   - ** 单独作为一个部分时匹配零个或多个路径部分
+//   - {a,b,c} 多选一，可以嵌套，也可以包含分隔符，如 {src,test/**}/*.go
+//   - POSIX风格可以使用 \ 转义特殊字符，Windows风格的 \ 是分隔符，需要使用 [*] 这样的字符类转义
+type Pattern struct {
+	source        string
+	flavour       Flavour
+	caseSensitive bool
+	alternatives  []patternAlternative // 花括号展开后的每一种选择
+}
+
+// 花括号展开后的一种选择
+type patternAlternative struct {
+	anchor   string           // 规范化之后的anchor
+	segments []patternSegment // anchor之后的每个部分
+}
+
+// 模式的一个部分，** 、字面量或者正则表达式三者之一
+type patternSegment struct {
+	doubleStar bool
+	literal    string
+	re         *regexp.Regexp
+}
+
+// 使用当前操作系统的路径风格编译模式串
+func CompilePattern(pattern string, options ...PatternOptions) (*Pattern, error) {
+	if runtime.GOOS == "windows" {
+		return CompilePatternWithFlavour(FlavourWindows, pattern, options...)
+	}
+	return CompilePatternWithFlavour(FlavourPosix, pattern, options...)
+}
+
+// 使用指定的路径风格编译模式串，风格决定了分隔符、转义字符和默认的大小写规则
+func CompilePatternWithFlavour(flavour Flavour, pattern string, options ...PatternOptions) (*Pattern, error) {
+	opts := common.ParseOptional(options, PatternOptions{})
+	caseSensitive := flavour == FlavourPosix
+	if opts.CaseSensitive != nil {
+		caseSensitive = *opts.CaseSensitive
+	}
+
+	escape := flavour == FlavourPosix // 只有POSIX风格支持 \ 转义
+	p := &Pattern{source: pattern, flavour: flavour, caseSensitive: caseSensitive}
+	for _, alt := range expandBraces(pattern, escape) {
+		var anchor, rest string
+		if flavour == FlavourWindows {
+			alt = nt.Clean(alt)
+			anchor = nt.Anchor(alt)
+			rest = strings.TrimPrefix(alt[len(anchor):], `\`)
+		} else {
+			alt = px.Clean(alt)
+			anchor = px.Anchor(alt)
+			rest = alt[len(anchor):]
+		}
+
+		compiled := patternAlternative{anchor: anchor}
+		if rest != "." && rest != "" {
+			for _, part := range strings.Split(rest, flavour.separator()) {
+				segment, err := compileSegment(part, escape, caseSensitive)
+				if err != nil {
+					return nil, common.WrapSub(err, ErrInvalidPattern, "invalid pattern %q", pattern)
+				}
+				compiled.segments = append(compiled.segments, segment)
+			}
+		}
+		p.alternatives = append(p.alternatives, compiled)
+	}
+	return p, nil
+}
+
+func MustCompilePattern(pattern string, options ...PatternOptions) *Pattern {
+	p, err := CompilePattern(pattern, options...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func MustCompilePatternWithFlavour(flavour Flavour, pattern string, options ...PatternOptions) *Pattern {
+	p, err := CompilePatternWithFlavour(flavour, pattern, options...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// 为 FullMatch 和 Match 方法编译字符串模式，没有指定大小写规则时使用风格的默认规则
+func compileMatchPattern(flavour Flavour, pattern string, caseSensitive []bool) (*Pattern, error) {
+	var options PatternOptions
+	if len(caseSensitive) > 0 {
+		options.CaseSensitive = &caseSensitive[0]
+	}
+	return CompilePatternWithFlavour(flavour, pattern, options)
+}
+
+// 返回原始的模式串
+func (p *Pattern) String() string {
+	return p.source
+}
+
+func (p *Pattern) Flavour() Flavour {
+	return p.flavour
+}
+
+func (p *Pattern) CaseSensitive() bool {
+	return p.caseSensitive
+}
+
+// 整个路径和模式匹配，anchor必须相同，** 不会匹配anchor
+func (p *Pattern) FullMatch(path IPurePath) bool {
+	anchor, names := splitAnchor(path)
+	for _, alt := range p.alternatives {
+		if p.equal(alt.anchor, anchor) && matchSegments(alt.segments, names, p.caseSensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// 如果模式是相对路径，则从右侧开始匹配，不会匹配anchor部分；模式带有anchor时和 FullMatch 相同
+func (p *Pattern) Match(path IPurePath) bool {
+	anchor, names := splitAnchor(path)
+	for _, alt := range p.alternatives {
+		if alt.anchor != "" {
+			if p.equal(alt.anchor, anchor) && matchSegments(alt.segments, names, p.caseSensitive) {
+				return true
+			}
+			continue
+		}
+		// ** 可以匹配零个名称，所以从只匹配不是 ** 的部分的位置开始尝试
+		for offset := max(len(names)-alt.fixedSegments(), 0); offset >= 0; offset-- {
+			if matchSegments(alt.segments, names[offset:], p.caseSensitive) {
+				return true
+			}
+			if !alt.hasDoubleStar() {
+				break // 没有 ** 时部分的数量必须相同，只需要尝试一次
+			}
+		}
+	}
+	return false
+}
+
+// PatternState 逐个名称匹配路径的中间状态，用于遍历目录时只进入可能匹配的子目录
+type PatternState struct {
+	pattern   *Pattern
+	positions [][]int // 每种选择中当前可能位于的模式部分的位置，等于部分数量表示已经完全匹配
+}
+
+// 模式是否带有anchor，花括号展开后任意一种选择带有anchor时返回true，例如 /a/*、C:\*、C:*
+func (p *Pattern) IsAnchored() bool {
+	for _, alt := range p.alternatives {
+		if alt.anchor != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// 返回空的相对路径的状态，用于匹配相对于某个目录的路径
+func (p *Pattern) Start() *PatternState {
+	return p.State(NewWithFlavour(p.flavour))
+}
+
+// 返回匹配完path之后的状态，State(path).Matched() 和 FullMatch(path) 的结果相同
+func (p *Pattern) State(path IPurePath) *PatternState {
+	anchor, names := splitAnchor(path)
+	state := &PatternState{pattern: p, positions: make([][]int, len(p.alternatives))}
+	for i, alt := range p.alternatives {
+		if p.equal(alt.anchor, anchor) {
+			state.positions[i] = alt.skipDoubleStars([]int{0})
+		}
+	}
+	for _, name := range names {
+		state = state.Next(name)
+	}
+	return state
+}
+
+// 返回在当前路径后面追加一个名称之后的状态
+func (s *PatternState) Next(name string) *PatternState {
+	next := &PatternState{pattern: s.pattern, positions: make([][]int, len(s.positions))}
+	for i, positions := range s.positions {
+		segments := s.pattern.alternatives[i].segments
+		var reached []int
+		for _, pos := range positions {
+			if pos == len(segments) {
+				continue
+			}
+			if segment := segments[pos]; segment.doubleStar {
+				reached = append(reached, pos) // ** 继续匹配更多的名称
+			} else if segment.match(name, s.pattern.caseSensitive) {
+				reached = append(reached, pos+1)
+			}
+		}
+		next.positions[i] = s.pattern.alternatives[i].skipDoubleStars(reached)
+	}
+	return next
+}
+
+// 当前路径是否完全匹配模式
+func (s *PatternState) Matched() bool {
+	for i, positions := range s.positions {
+		if slices.Contains(positions, len(s.pattern.alternatives[i].segments)) {
+			return true
+		}
+	}
+	return false
+}
+
+// 当前路径下面的路径是否还有可能匹配模式，返回false时不需要读取当前目录
+func (s *PatternState) CanDescend() bool {
+	for i, positions := range s.positions {
+		for _, pos := range positions {
+			if pos < len(s.pattern.alternatives[i].segments) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 如果下一个名称只能匹配字面量，返回所有可能的名称，调用者可以直接检查这些名称是否存在，而不需要读取整个目录
+func (s *PatternState) Literals() (names []string, ok bool) {
+	for i, positions := range s.positions {
+		segments := s.pattern.alternatives[i].segments
+		for _, pos := range positions {
+			if pos == len(segments) {
+				continue
+			}
+			if segment := segments[pos]; segment.doubleStar || segment.re != nil {
+				return nil, false
+			} else if !slices.Contains(names, segment.literal) {
+				names = append(names, segment.literal)
+			}
+		}
+	}
+	return names, true
+}
+
+// 下一个名称是否匹配某个字面量部分，而不只是被通配符匹配
+func (s *PatternState) MatchesLiteral(name string) bool {
+	for i, positions := range s.positions {
+		segments := s.pattern.alternatives[i].segments
+		for _, pos := range positions {
+			if pos == len(segments) {
+				continue
+			}
+			if segment := segments[pos]; !segment.doubleStar && segment.re == nil &&
+				segment.match(name, s.pattern.caseSensitive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 将位置集合中指向 ** 的位置扩展到 ** 之后，因为 ** 可以匹配零个名称，返回排序去重后的集合
+func (a patternAlternative) skipDoubleStars(positions []int) []int {
+	var result []int
+	for _, pos := range positions {
+		for ; ; pos++ {
+			result = append(result, pos)
+			if pos == len(a.segments) || !a.segments[pos].doubleStar {
+				break
+			}
+		}
+	}
+	slices.Sort(result)
+	return slices.Compact(result)
+}
+
+func (p *Pattern) equal(a, b string) bool {
+	if p.caseSensitive {
+		return a == b
+	}
+	return strings.EqualFold(a, b)
+}
+
+func (a patternAlternative) hasDoubleStar() bool {
+	for _, segment := range a.segments {
+		if segment.doubleStar {
+			return true
+		}
+	}
+	return false
+}
+
+// 不是 ** 的部分的数量，每个这样的部分恰好匹配一个名称
+func (a patternAlternative) fixedSegments() int {
+	n := 0
+	for _, segment := range a.segments {
+		if !segment.doubleStar {
+			n++
+		}
+	}
+	return n
+}
+
+func (s patternSegment) match(name string, caseSensitive bool) bool {
+	if s.re != nil {
+		return s.re.MatchString(name)
+	}
+	if caseSensitive {
+		return s.literal == name
+	}
+	return strings.EqualFold(s.literal, name)
+}
+
+// 将路径拆分为anchor和剩余的名称部分
+func splitAnchor(path IPurePath) (anchor string, names []string) {
+	anchor, names = path.Anchor(), path.Parts()
+	if anchor != "" {
+		names = names[1:]
+	}
+	return anchor, names
+}
+
+// 使用动态规划匹配模式的各个部分和路径的各个名称，** 可以匹配零个或多个名称
+func matchSegments(segments []patternSegment, names []string, caseSensitive bool) bool {
+	// matched[j] 表示已经处理的模式部分能否恰好匹配前j个名称
+	matched := make([]bool, len(names)+1)
+	matched[0] = true
+	for _, segment := range segments {
+		next := make([]bool, len(names)+1)
+		if segment.doubleStar {
+			reachable := false
+			for j := range next {
+				reachable = reachable || matched[j]
+				next[j] = reachable
+			}
+		} else {
+			for j := 1; j < len(next); j++ {
+				next[j] = matched[j-1] && segment.match(names[j-1], caseSensitive)
+			}
+		}
+		matched = next
+	}
+	return matched[len(names)]
+}
+
+// 编译模式的一个部分，没有通配符时作为字面量比较
+func compileSegment(part string, escape, caseSensitive bool) (patternSegment, error) {
+	if part == "**" {
+		return patternSegment{doubleStar: true}, nil
+	}
+
+	var expr, literal strings.Builder
+	hasMeta := false
+	for i := 0; i < len(part); i++ {
+		c := part[i]
+		switch {
+		case c == '\\' && escape && i+1 < len(part):
+			i++
+			literal.WriteByte(part[i])
+			expr.WriteString(regexp.QuoteMeta(part[i : i+1]))
+		case c == '*':
+			hasMeta = true
+			for i+1 < len(part) && part[i+1] == '*' { // 连续的 * 等同于一个
+				i++
+			}
+			expr.WriteString(".*")
+		case c == '?':
+			hasMeta = true
+			expr.WriteString(".")
+		case c == '[':
+			class, end, err := translateClass(part, i, escape)
+			if err != nil {
+				return patternSegment{}, err
+			}
+			hasMeta = true
+			expr.WriteString(class)
+			i = end
+		default:
+			literal.WriteByte(c)
+			expr.WriteString(regexp.QuoteMeta(part[i : i+1]))
+		}
+	}
+
+	if !hasMeta {
+		return patternSegment{literal: literal.String()}, nil
+	}
+	flags := "(?s)"
+	if !caseSensitive {
+		flags = "(?is)"
+	}
+	re, err := regexp.Compile(flags + "^" + expr.String() + "$")
+	if err != nil {
+		return patternSegment{}, err
+	}
+	return patternSegment{re: re}, nil
+}
+
+// 将从start开始的字符类 [...] 转换成正则表达式，返回结束的 ] 的位置
+func translateClass(part string, start int, escape bool) (class string, end int, err error) {
+	var b strings.Builder
+	b.WriteByte('[')
+	i := start + 1
+	if i < len(part) && (part[i] == '!' || part[i] == '^') {
+		b.WriteByte('^')
+		i++
+	}
+	for first := true; i < len(part); i, first = i+1, false {
+		c := part[i]
+		switch {
+		case c == ']' && !first: // 紧跟在 [ 或 [! 之后的 ] 是普通字符
+			b.WriteByte(']')
+			return b.String(), i, nil
+		case c == '\\' && escape && i+1 < len(part):
+			i++
+			writeClassChar(&b, part[i])
+		case c == '-' && !first && i+1 < len(part) && part[i+1] != ']': // 范围
+			b.WriteByte('-')
+		default:
+			writeClassChar(&b, c)
+		}
+	}
+	return "", 0, common.WrapMsg(ErrInvalidPattern, "unclosed character class in %q", part)
+}
+
+// 将字符类中的一个字节写入正则表达式，在字符类中有特殊含义的字符需要转义，多字节字符的各个字节会按顺序原样写入
+func writeClassChar(b *strings.Builder, c byte) {
+	if strings.IndexByte(`\^[]-`, c) >= 0 {
+		b.WriteByte('\\')
+	}
+	b.WriteByte(c)
+}
+
+// 展开模式中的花括号 {a,b,c}，可以嵌套。字符类中的花括号、被转义的花括号、没有逗号或者不成对的花括号都作为普通字符
+func expandBraces(pattern string, escape bool) []string {
+	open, commas, end := findBraces(pattern, escape)
+	if open < 0 {
+		return []string{pattern}
+	}
+
+	prefix, suffix := pattern[:open], pattern[end+1:]
+	bounds := append(append([]int{open}, commas...), end) // 每个选择位于相邻的两个边界之间
+	var result []string
+	for i := 0; i+1 < len(bounds); i++ {
+		result = append(result, expandBraces(prefix+pattern[bounds[i]+1:bounds[i+1]]+suffix, escape)...)
+	}
+	return result
+}
+
+// 找到第一组可以展开的花括号，返回 { 的位置、顶层逗号的位置和 } 的位置，没有时open为-1
+func findBraces(pattern string, escape bool) (open int, commas []int, end int) {
+	for open = nextBrace(pattern, 0, escape); open >= 0; open = nextBrace(pattern, open+1, escape) {
+		depth := 0
+		commas = nil
+		for i := open; i < len(pattern); i++ {
+			switch c := pattern[i]; {
+			case c == '\\' && escape:
+				i++ // 跳过被转义的字符
+			case c == '[':
+				if j := classEnd(pattern, i, escape); j > 0 {
+					i = j // 跳过字符类
+				}
+			case c == '{':
+				depth++
+			case c == ',' && depth == 1:
+				commas = append(commas, i)
+			case c == '}':
+				depth--
+				if depth == 0 {
+					if len(commas) > 0 {
+						return open, commas, i
+					}
+					i = len(pattern) // 没有逗号，作为普通字符，继续查找下一个 {
+				}
+			}
+		}
+	}
+	return -1, nil, -1
+}
+
+// 返回从start开始第一个没有被转义并且不在字符类中的 { 的位置，没有时返回-1
+func nextBrace(pattern string, start int, escape bool) int {
+	for i := start; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && escape:
+			i++
+		case c == '[':
+			if j := classEnd(pattern, i, escape); j > 0 {
+				i = j
+			}
+		case c == '{':
+			return i
+		}
+	}
+	return -1
+}
+
+// 返回从start开始的字符类的结束位置，没有结束的 ] 时返回-1
+func classEnd(pattern string, start int, escape bool) int {
+	i := start + 1
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		i++
+	}
+	for first := true; i < len(pattern); i, first = i+1, false {
+		switch {
+		case pattern[i] == ']' && !first:
+			return i
+		case pattern[i] == '\\' && escape:
+			i++
+		}
+	}
+	return -1
+}
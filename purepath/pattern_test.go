@@ -0,0 +1,214 @@
+package purepath
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPattern_FullMatch(t *testing.T) {
+	testcases := []struct {
+		flavour Flavour
+		pattern string
+		input   string
+		output  bool
+	}{
+		// 花括号
+		{FlavourPosix, "src/*.{go,mod}", "src/main.go", true},
+		{FlavourPosix, "src/*.{go,mod}", "src/go.mod", true},
+		{FlavourPosix, "src/*.{go,mod}", "src/go.sum", false},
+		{FlavourPosix, "{src,test/**}/*.go", "test/a/b/x.go", true},
+		{FlavourPosix, "{src,test/**}/*.go", "src/a/x.go", false},
+		{FlavourPosix, "a{b,c{d,e}}f", "acef", true},
+		{FlavourPosix, "a{b,c{d,e}}f", "acf", false},
+		{FlavourPosix, "a{b}c", "a{b}c", true}, // 没有逗号的花括号是普通字符
+		{FlavourPosix, "a{b,c", "a{b,c", true}, // 不成对的花括号是普通字符
+		{FlavourPosix, `a\{b,c}`, "a{b,c}", true},
+		{FlavourPosix, "[{]a,b}", "{a,b}", true},
+		// 字符类
+		{FlavourPosix, "file[0-9].txt", "file7.txt", true},
+		{FlavourPosix, "file[!0-9].txt", "file7.txt", false},
+		{FlavourPosix, "file[!0-9].txt", "filex.txt", true},
+		{FlavourPosix, "file[^0-9].txt", "filex.txt", true},
+		{FlavourPosix, "[]x]", "]", true},
+		{FlavourPosix, "[a-]", "-", true},
+		{FlavourPosix, "[é]", "é", true},
+		{FlavourPosix, `[\]]`, "]", true},
+		// 转义
+		{FlavourPosix, `a\*b`, "a*b", true},
+		{FlavourPosix, `a\*b`, "axb", false},
+		{FlavourPosix, `a\?`, "a?", true},
+		// **
+		{FlavourPosix, "/a/**", "/a", true},
+		{FlavourPosix, "/a/**/b/**/c", "/a/x/b/y/z/c", true},
+		{FlavourPosix, "/a/**/b", "/a/x/c", false},
+		{FlavourPosix, "**/*.go", "/a/x.go", false}, // ** 不会匹配anchor
+		{FlavourPosix, "a**b", "axxb", true},        // 不单独作为一部分时和 * 相同
+		{FlavourPosix, "a**b", "ax/xb", false},
+		// Windows风格
+		{FlavourWindows, `C:\src\*.{cs,csproj}`, `c:\SRC\app.CSPROJ`, true},
+		{FlavourWindows, `C:/src/**/[*].txt`, `c:\src\a\*.txt`, true},
+		{FlavourWindows, `C:/src/**/[*].txt`, `c:\src\a\b.txt`, false},
+		{FlavourWindows, `\\server\share\**`, `\\SERVER\share\x\y`, true},
+		{FlavourWindows, `{c,d}:\*`, `d:\x`, true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.pattern+" matches "+tc.input, func(t *testing.T) {
+			pattern, err := CompilePatternWithFlavour(tc.flavour, tc.pattern)
+			if err != nil {
+				t.Fatalf("Failed to compile pattern: %v", err)
+			}
+			path := NewWithFlavour(tc.flavour, tc.input)
+			if result := pattern.FullMatch(path); result != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result)
+			}
+			if result := path.FullMatchPattern(pattern); result != tc.output {
+				t.Errorf("FullMatchPattern: expected %v, got %v", tc.output, result)
+			}
+		})
+	}
+}
+
+func TestPattern_Match(t *testing.T) {
+	testcases := []struct {
+		flavour Flavour
+		pattern string
+		input   string
+		output  bool
+	}{
+		{FlavourPosix, "*.{go,md}", "/a/b/readme.md", true},
+		{FlavourPosix, "b/*.go", "/a/b/x.go", true},
+		{FlavourPosix, "a/*.go", "/a/b/x.go", false},
+		{FlavourPosix, "a/**/*.go", "/root/a/b/c/x.go", true},
+		{FlavourPosix, "/a/*.go", "/root/a/x.go", false},
+		{FlavourWindows, `out\*.DLL`, `C:\build\out\app.dll`, true},
+		{FlavourWindows, `c:*.dll`, `c:\app.dll`, false},
+		{FlavourPosix, "**/a.md", "a.md", true}, // 开头的 ** 匹配零个名称
+		{FlavourPosix, "**/b/**/a.md", "b/a.md", true},
+		{FlavourWindows, `**\a.md`, `a.md`, true},
+		{FlavourWindows, `**\a.md`, `C:\a.md`, true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.pattern+" matches "+tc.input, func(t *testing.T) {
+			pattern := MustCompilePatternWithFlavour(tc.flavour, tc.pattern)
+			if result := NewWithFlavour(tc.flavour, tc.input).MatchPattern(pattern); result != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result)
+			}
+		})
+	}
+}
+
+func TestPattern_State(t *testing.T) {
+	pattern := MustCompilePatternWithFlavour(FlavourPosix, "{src/pkg/*.go,docs/**/*.md}")
+	testcases := []struct {
+		input      string
+		matched    bool
+		canDescend bool
+		literals   []string // nil表示下一部分不全是字面量
+	}{
+		{".", false, true, []string{"src", "docs"}},
+		{"src", false, true, []string{"pkg"}},
+		{"src/pkg", false, true, nil},
+		{"src/pkg/a.go", true, false, []string{}},
+		{"src/other", false, false, []string{}},
+		{"docs", false, true, nil},
+		{"docs/a/b/c.md", true, true, nil},
+		{"/src", false, false, []string{}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			path := NewPosix(tc.input)
+			state := pattern.State(path)
+			if result := state.Matched(); result != tc.matched || result != pattern.FullMatch(path) {
+				t.Errorf("Matched: expected %v, got %v", tc.matched, result)
+			}
+			if result := state.CanDescend(); result != tc.canDescend {
+				t.Errorf("CanDescend: expected %v, got %v", tc.canDescend, result)
+			}
+			literals, ok := state.Literals()
+			if ok != (tc.literals != nil) || (ok && !slices.Equal(literals, tc.literals) && len(literals)+len(tc.literals) > 0) {
+				t.Errorf("Literals: expected %v, got %v (%v)", tc.literals, literals, ok)
+			}
+		})
+	}
+}
+
+func TestPatternState_MatchesLiteral(t *testing.T) {
+	pattern := MustCompilePatternWithFlavour(FlavourPosix, "{.git/*,**/.env,*.md}")
+	testcases := []struct {
+		input  string
+		name   string
+		output bool
+	}{
+		{".", ".git", true},
+		{".", ".env", true},
+		{".", ".hidden.md", false}, // 只被 *.md 匹配
+		{"a/b", ".env", true},
+		{".git", "config", false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.input+"/"+tc.name, func(t *testing.T) {
+			if result := pattern.State(NewPosix(tc.input)).MatchesLiteral(tc.name); result != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result)
+			}
+		})
+	}
+}
+
+func TestPattern_IsAnchored(t *testing.T) {
+	testcases := []struct {
+		flavour Flavour
+		pattern string
+		output  bool
+	}{
+		{FlavourPosix, "a/*.go", false},
+		{FlavourPosix, "/a/*.go", true},
+		{FlavourPosix, "{a,/b}/*.go", true},
+		{FlavourWindows, `*.dll`, false},
+		{FlavourWindows, `c:*.dll`, true},
+		{FlavourWindows, `\\server\share\*`, true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			if result := MustCompilePatternWithFlavour(tc.flavour, tc.pattern).IsAnchored(); result != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result)
+			}
+		})
+	}
+}
+
+func TestPattern_CaseSensitive(t *testing.T) {
+	yes, no := true, false
+	testcases := []struct {
+		flavour       Flavour
+		caseSensitive *bool
+		output        bool
+	}{
+		{FlavourPosix, nil, false},
+		{FlavourPosix, &no, true},
+		{FlavourWindows, nil, true},
+		{FlavourWindows, &yes, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.flavour.String(), func(t *testing.T) {
+			pattern := MustCompilePatternWithFlavour(tc.flavour, "SRC/*.GO", PatternOptions{CaseSensitive: tc.caseSensitive})
+			if result := pattern.FullMatch(NewWithFlavour(tc.flavour, "src/main.go")); result != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result)
+			}
+		})
+	}
+}
+
+func TestCompilePattern_Invalid(t *testing.T) {
+	for _, pattern := range []string{"a[b", "[!", "x/[a-"} {
+		t.Run(pattern, func(t *testing.T) {
+			_, err := CompilePatternWithFlavour(FlavourPosix, pattern)
+			if !errors.Is(err, ErrInvalidPattern) {
+				t.Errorf("Expected ErrInvalidPattern, got %v", err)
+			}
+			if NewPosix("a[b").FullMatch(pattern) {
+				t.Errorf("Invalid pattern should not match")
+			}
+		})
+	}
+}
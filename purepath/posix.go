@@ -0,0 +1,435 @@
+package purepath
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/viocha/go-pathlib/internal/common"
+	nt "github.com/viocha/go-pathlib/purepath/ntpath"
+	px "github.com/viocha/go-pathlib/purepath/posixpath"
+)
+
+// PurePosixPath POSIX文件系统路径的纯路径实现
+type PurePosixPath struct {
+	path string
+}
+
+var _ IPurePath = (*PurePosixPath)(nil) // 确保实现了IPurePath接口
+
+// 创建新的POSIX纯路径对象
+func NewPurePosixPath(segments ...string) *PurePosixPath {
+	if len(segments) == 0 {
+		return &PurePosixPath{path: "."}
+	}
+
+	path := px.Clean(segments[0])
+	for _, seg := range segments[1:] {
+		if seg == "" { // 空路径段不影响结果
+			continue
+		}
+		path = px.Join(path, seg) // 绝对路径会覆盖前面的路径，注意 .. 也会被解析，和python的不同
+	}
+	return &PurePosixPath{path: path}
+}
+
+// 返回路径的字符串表示
+func (p *PurePosixPath) String() string {
+	return p.path
+}
+
+// 返回路径的所有组件
+func (p *PurePosixPath) Parts() []string {
+	return px.Parts(p.path)
+}
+
+// POSIX路径没有驱动器，始终返回空字符串
+func (p *PurePosixPath) Drive() string {
+	return px.Drive(p.path)
+}
+
+// 返回根路径标识符，可能为 / 或 //，相对路径返回空字符串
+func (p *PurePosixPath) Root() string {
+	return px.Root(p.path)
+}
+
+// 返回驱动器和根的联合，对于POSIX路径等于根
+func (p *PurePosixPath) Anchor() string {
+	return px.Anchor(p.path)
+}
+
+// 返回此路径的逻辑父路径，如果没有父路径，则返回当前路径
+func (p *PurePosixPath) Parent() IPurePath {
+	anchor, parts := p.Anchor(), p.Parts()
+	if len(parts) == 0 {
+		return NewPurePosixPath(".")
+	}
+	if len(parts) == 1 {
+		if anchor != "" {
+			return NewPurePosixPath(parts[0]) // 如果等于anchor，保留anchor不变
+		} else {
+			return NewPurePosixPath(".") // 没有anchor的情况，返回当前路径.
+		}
+	}
+	// 移除最后一个部分
+	newParts := parts[:len(parts)-1]
+	var newPath string
+	if anchor == "" { // 常规的相对路径
+		newPath = strings.Join(newParts, "/")
+	} else { // anchor中已经包含了/分隔符
+		newPath = parts[0] + strings.Join(newParts[1:], "/")
+	}
+	return NewPurePosixPath(newPath)
+}
+
+// 返回此路径的所有逻辑祖先路径，可能为空数组
+func (p *PurePosixPath) Parents() []IPurePath {
+	if p.String() == "." || p.String() == p.Anchor() {
+		return nil
+	}
+	result := []IPurePath{}
+	for cur := p.Parent(); ; cur = cur.Parent() {
+		result = append(result, cur)
+		if cur.String() == "." || cur.String() == cur.Anchor() {
+			break // 到达根路径或当前路径时停止
+		}
+	}
+	return result
+}
+
+// 返回最后一个路径组件
+func (p *PurePosixPath) Name() string {
+	parts := p.Parts()
+	if len(parts) == 0 {
+		return ""
+	}
+	if p.Anchor() != "" && len(parts) == 1 {
+		return "" // 只有根路径
+	}
+	return parts[len(parts)-1]
+}
+
+// 返回文件扩展名，以点开头的隐藏文件名不视为扩展名
+func (p *PurePosixPath) Suffix() string {
+	suffixes := p.Suffixes()
+	if len(suffixes) == 0 {
+		return ""
+	}
+	return suffixes[len(suffixes)-1] // 返回最后一个扩展名
+}
+
+// 返回所有文件扩展名，以点开头的隐藏文件名不视为扩展名
+func (p *PurePosixPath) Suffixes() []string {
+	name := p.Name()
+	if name == "" || strings.HasSuffix(name, ".") {
+		return nil
+	}
+
+	// 去掉开头的点，避免把隐藏文件名当作扩展名
+	nameParts := strings.Split(strings.TrimLeft(name, "."), ".")
+	if len(nameParts) <= 1 {
+		return nil // 没有扩展名
+	}
+
+	suffixes := nameParts[1:]
+	for i, suffix := range suffixes {
+		suffixes[i] = "." + suffix // 添加点前缀
+	}
+	return suffixes
+}
+
+// 返回去除扩展名的文件名
+func (p *PurePosixPath) Stem() string {
+	return strings.TrimSuffix(p.Name(), p.Suffix()) // 去掉最后一个扩展名
+}
+
+// 设置新的anchor，返回新的路径对象，anchor可以是 / // ""
+func (p *PurePosixPath) WithAnchor(anchor string) (IPurePath, error) {
+	if err := px.ValidateAnchor(anchor); err != nil {
+		return nil, err
+	}
+
+	parts := p.Parts()
+	if p.Anchor() != "" {
+		parts = parts[1:] // 如果当前路径有anchor，则先去掉
+	}
+	return NewPurePosixPath(anchor + strings.Join(parts, "/")), nil // anchor中已经包含了/分隔符
+}
+
+// 返回修改name后的新路径
+func (p *PurePosixPath) WithName(name string) (IPurePath, error) {
+	if p.Name() == "" {
+		return nil, common.WrapMsg(px.ErrNoName, "cannot set name %q on path without name", name)
+	}
+	if err := px.ValidateName(name); err != nil {
+		return nil, err
+	}
+	return NewPurePosixPath(p.path, "..", name), nil
+}
+
+// 返回修改父路径后的新路径，parent必须是一个有效的IPurePath对象
+func (p *PurePosixPath) WithParent(parent IPurePath) (IPurePath, error) {
+	name := p.Name()
+	if name == "" {
+		return nil, common.WrapMsg(px.ErrNoName, "cannot set parent on path without name")
+	}
+	return parent.Join(name), nil
+}
+
+// 返回修改stem后的新路径
+func (p *PurePosixPath) WithStem(stem string) (IPurePath, error) {
+	if p.Name() == "" {
+		return nil, common.WrapMsg(px.ErrNoName, "cannot set stem %q on empty name", stem)
+	}
+	newName := stem + p.Suffix() // 保留原有扩展名
+
+	if err := px.ValidateName(newName); err != nil {
+		return nil, err
+	}
+	return NewPurePosixPath(p.path, "..", newName), nil
+}
+
+// 返回修改suffix后的新路径，必须存在文件名，否则返回ErrNoName错误，suffix必须以点开头，否则返回ErrInvalidSuffix错误
+func (p *PurePosixPath) WithSuffix(suffix string) (IPurePath, error) {
+	if p.Name() == "" {
+		return nil, common.WrapMsg(px.ErrNoName, "cannot set suffix %q on empty name", suffix)
+	}
+	if !strings.HasPrefix(suffix, ".") {
+		return nil, common.WrapMsg(px.ErrInvalidSuffix, "suffix %q must start with a dot", suffix)
+	}
+	newName := p.Stem() + suffix // 替换最后一个扩展名
+
+	if err := px.ValidateName(newName); err != nil {
+		return nil, err
+	}
+	return NewPurePosixPath(p.path, "..", newName), nil
+}
+
+// 返回使用正斜杠的路径字符串，POSIX路径本身就使用正斜杠
+func (p *PurePosixPath) AsPosix() string {
+	return p.path
+}
+
+func (p *PurePosixPath) Flavour() Flavour {
+	return FlavourPosix
+}
+
+// POSIX路径区分大小写，直接返回路径字符串
+func (p *PurePosixPath) Key() string {
+	return p.path
+}
+
+// 区分大小写判断路径是否相等
+func (p *PurePosixPath) Equal(other IPurePath) bool {
+	return other != nil && other.Flavour() == FlavourPosix && p.Key() == other.Key()
+}
+
+// 先比较风格，再区分大小写逐个比较路径组件，nil排在所有路径之前
+func (p *PurePosixPath) Compare(other IPurePath) int {
+	if other == nil {
+		return 1
+	}
+	if c := cmp.Compare(p.Flavour(), other.Flavour()); c != 0 {
+		return c
+	}
+	return slices.Compare(px.Parts(p.Key()), px.Parts(other.Key()))
+}
+
+// 转换成Windows风格的路径，/ 会被替换为 \，名称中的 \ 也会被视为分隔符
+func (p *PurePosixPath) ToWindows() IPurePath {
+	return NewPureWindowsPath(p.path)
+}
+
+func (p *PurePosixPath) ToPosix() IPurePath {
+	return p
+}
+
+// 先转换成Windows路径，再转换成盘符挂载在 mountRoot 下的POSIX路径，如 c:/foo 转换成 /mnt/c/foo
+func (p *PurePosixPath) ToMountPath(mountRoot string) (IPurePath, error) {
+	return p.ToWindows().ToMountPath(mountRoot)
+}
+
+// 转换成Windows路径，mountRoot 下的第一级目录作为盘符，以 // 开头的路径转换成UNC路径
+func (p *PurePosixPath) FromMountPath(mountRoot string) (IPurePath, error) {
+	path, err := nt.FromMountPath(p.path, mountRoot)
+	if err != nil {
+		return nil, err
+	}
+	return NewPureWindowsPath(path), nil
+}
+
+// 返回此路径是否为绝对路径
+func (p *PurePosixPath) IsAbs() bool {
+	return p.Root() != ""
+}
+
+// 返回此路径是否相对于other路径，walkUp参数表示是否允许向上遍历，区分大小写
+func (p *PurePosixPath) IsRelTo(other IPurePath, walkUp ...bool) bool {
+	return isRelTo(p, other, common.ParseOptional(walkUp, true)) // 默认允许向上遍历，和python不同
+}
+
+// 是否是other的真祖先路径，逐个比较路径组件，路径本身不算作祖先
+func (p *PurePosixPath) IsAncestorOf(other IPurePath) bool {
+	return isAncestor(p, other)
+}
+
+// 是否是other的真后代路径，逐个比较路径组件，路径本身不算作后代
+func (p *PurePosixPath) IsDescendantOf(other IPurePath) bool {
+	return isAncestor(other, p)
+}
+
+// 返回此路径和others的最长公共祖先路径，可能是路径本身，没有公共祖先时返回错误
+func (p *PurePosixPath) CommonAncestor(others ...IPurePath) (IPurePath, error) {
+	return commonAncestor(p, others)
+}
+
+func (p *PurePosixPath) Validate() error {
+	return px.ValidatePath(p.path)
+}
+
+// 让所有名称都变得合法，不会改变anchor部分
+func (p *PurePosixPath) ToValid() IPurePath {
+	anchor, parts := p.Anchor(), p.Parts()
+	if anchor != "" {
+		parts = parts[1:] // 去掉anchor部分
+	}
+	for i, part := range parts {
+		parts[i] = px.ToValidName(part) // 让每个部分的名称合法
+	}
+	return NewPurePosixPath(anchor + strings.Join(parts, "/")) // 重新组合路径
+}
+
+// 将路径与给定的路径段组合
+func (p *PurePosixPath) Join(segments ...string) IPurePath {
+	segments = slices.Insert(segments, 0, p.path) // 将当前路径作为第一个元素
+	return NewPurePosixPath(segments...)
+}
+
+func (p *PurePosixPath) JoinPath(segments ...IPurePath) IPurePath {
+	strSegments := []string{}
+	for _, seg := range segments {
+		strSegments = append(strSegments, seg.String())
+	}
+	return p.Join(strSegments...)
+}
+
+func (p *PurePosixPath) JoinForFile(path string) IPurePath {
+	return p.Parent().Join(path) // 使用父路径进行组合
+}
+
+func (p *PurePosixPath) JoinPathForFile(path IPurePath) IPurePath {
+	return p.Parent().JoinPath(path) // 使用父路径进行组合
+}
+
+// 将此路径与pattern完全匹配，默认区分大小写，支持的语法见 Pattern，不合法的模式不会匹配任何路径。
+// 需要使用同一个模式匹配大量路径时，应该先使用 CompilePattern 编译，然后调用 FullMatchPattern
+func (p *PurePosixPath) FullMatch(pattern string, caseSensitive ...bool) bool {
+	compiled, err := compileMatchPattern(FlavourPosix, pattern, caseSensitive)
+	return err == nil && compiled.FullMatch(p)
+}
+
+// 将此路径与编译后的模式完全匹配
+func (p *PurePosixPath) FullMatchPattern(pattern *Pattern) bool {
+	return pattern.FullMatch(p)
+}
+
+// 将此路径与pattern匹配，如果pattern是相对路径，则从右侧开始匹配
+func (p *PurePosixPath) Match(pattern string, caseSensitive ...bool) bool {
+	compiled, err := compileMatchPattern(FlavourPosix, pattern, caseSensitive)
+	return err == nil && compiled.Match(p)
+}
+
+// 将此路径与编译后的模式匹配，如果模式是相对路径，则从右侧开始匹配
+func (p *PurePosixPath) MatchPattern(pattern *Pattern) bool {
+	return pattern.Match(p)
+}
+
+// 计算此路径相对于other的版本
+func (p *PurePosixPath) RelTo(other IPurePath, walkUp ...bool) (IPurePath, error) {
+	return relTo(p, other, common.ParseOptional(walkUp, true)) // 默认允许向上遍历
+}
+
+// 基于目标文件的相对路径，会先获取目标文件的父路径，然后计算相对路径
+func (p *PurePosixPath) RelToFile(other IPurePath, walkUp ...bool) (IPurePath, error) {
+	return p.RelTo(other.Parent(), walkUp...)
+}
+
+func (p *PurePosixPath) MustWithAnchor(anchor string) IPurePath {
+	path, err := p.WithAnchor(anchor)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PurePosixPath) MustWithName(name string) IPurePath {
+	path, err := p.WithName(name)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PurePosixPath) MustWithStem(stem string) IPurePath {
+	path, err := p.WithStem(stem)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PurePosixPath) MustWithSuffix(suffix string) IPurePath {
+	path, err := p.WithSuffix(suffix)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PurePosixPath) MustWithParent(parent IPurePath) IPurePath {
+	path, err := p.WithParent(parent)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PurePosixPath) MustRelTo(other IPurePath, walkUp ...bool) IPurePath {
+	path, err := p.RelTo(other, walkUp...)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PurePosixPath) MustRelToFile(other IPurePath, walkUp ...bool) IPurePath {
+	path, err := p.RelToFile(other, walkUp...)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PurePosixPath) MustToMountPath(mountRoot string) IPurePath {
+	path, err := p.ToMountPath(mountRoot)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PurePosixPath) MustFromMountPath(mountRoot string) IPurePath {
+	path, err := p.FromMountPath(mountRoot)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PurePosixPath) MustCommonAncestor(others ...IPurePath) IPurePath {
+	path, err := p.CommonAncestor(others...)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
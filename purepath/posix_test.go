@@ -0,0 +1,461 @@
+package purepath
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	nt "github.com/viocha/go-pathlib/purepath/ntpath"
+	px "github.com/viocha/go-pathlib/purepath/posixpath"
+)
+
+func TestNewPurePosixPath(t *testing.T) {
+	runTask(t, []struct {
+		input  []string
+		output string
+	}{
+		// 绝对路径覆盖前面的路径
+		{[]string{"/usr", "/etc"}, "/etc"},
+		{[]string{"a/b", "//server/share"}, "//server/share"},
+		// 处理空路径
+		{[]string{}, `.`},
+		{[]string{""}, `.`},
+		{[]string{"a", ""}, `a`},
+		// 处理.和..路径
+		{[]string{`a/./b`}, `a/b`},
+		{[]string{`a/../b`}, `b`},
+		{[]string{`a`, `../b`}, `b`},
+		{[]string{`../b`}, `../b`},
+		{[]string{`/..`}, `/`},
+		// 斜杠去重，末尾斜杠移除
+		{[]string{`./a//b/.`}, `a/b`},
+		{[]string{"/foo/"}, `/foo`},
+		// 反斜杠是普通字符
+		{[]string{`a\b`}, `a\b`},
+		// 开头恰好两个斜杠会被保留，三个及以上的斜杠视为一个
+		{[]string{"//foo/bar"}, `//foo/bar`},
+		{[]string{"///foo/bar"}, `/foo/bar`},
+		{[]string{"//"}, `//`},
+	}, func(input []string) string {
+		return NewPurePosixPath(input...).String()
+	})
+}
+
+func TestPurePosixPath_Parts(t *testing.T) {
+	runTask(t, []struct {
+		input  string
+		output []string
+	}{
+		{"", nil},
+		{".", nil},
+		{"/", []string{"/"}},
+		{"//", []string{"//"}},
+		{"/a", []string{"/", "a"}},
+		{"//a/b", []string{"//", "a", "b"}},
+		{"foo/bar/", []string{"foo", "bar"}},
+		{`c:\foo`, []string{`c:\foo`}},
+	}, func(input string) []string {
+		return NewPurePosixPath(input).Parts()
+	})
+}
+
+func TestPurePosixPath_Anchor(t *testing.T) {
+	runTask(t, []struct {
+		input  string
+		output [3]string
+	}{
+		{"/foo/bar", [3]string{"", "/", "/"}},
+		{"//foo/bar", [3]string{"", "//", "//"}},
+		{"///foo/bar", [3]string{"", "/", "/"}},
+		{"foo/bar", [3]string{"", "", ""}},
+		{"", [3]string{"", "", ""}},
+	}, func(input string) [3]string {
+		p := NewPurePosixPath(input)
+		return [3]string{p.Drive(), p.Root(), p.Anchor()}
+	})
+}
+
+func TestPurePosixPath_Parent(t *testing.T) {
+	runTask(t, []struct {
+		input  string
+		output string
+	}{
+		{"/foo/bar", "/foo"},
+		{"/foo", "/"},
+		{"/", "/"},
+		{"//foo", "//"},
+		{"foo/bar", "foo"},
+		{"a", "."},
+		{".", "."},
+	}, func(input string) string {
+		return NewPurePosixPath(input).Parent().String()
+	})
+}
+
+func TestPurePosixPath_Parents(t *testing.T) {
+	runTask(t, []struct {
+		input  string
+		output []string
+	}{
+		{"/foo/bar", []string{"/foo", "/"}},
+		{"foo/bar/baz", []string{"foo/bar", "foo", "."}},
+		{"/", nil},
+		{".", nil},
+	}, func(input string) []string {
+		var result []string
+		for _, p := range NewPurePosixPath(input).Parents() {
+			result = append(result, p.String())
+		}
+		return result
+	})
+}
+
+func TestPurePosixPath_NameStemSuffix(t *testing.T) {
+	runTask(t, []struct {
+		input  string
+		output []any
+	}{
+		{"/foo/bar.tar.gz", []any{"bar.tar.gz", "bar.tar", ".gz", []string{".tar", ".gz"}}},
+		{"/foo/.bashrc", []any{".bashrc", ".bashrc", "", []string(nil)}},
+		{"/foo/.config.json", []any{".config.json", ".config", ".json", []string{".json"}}},
+		{"foo.", []any{"foo.", "foo.", "", []string(nil)}},
+		{"/", []any{"", "", "", []string(nil)}},
+		{".", []any{"", "", "", []string(nil)}},
+	}, func(input string) []any {
+		p := NewPurePosixPath(input)
+		return []any{p.Name(), p.Stem(), p.Suffix(), p.Suffixes()}
+	})
+}
+
+func TestPurePosixPath_IsAbs(t *testing.T) {
+	runTask(t, []struct {
+		input  string
+		output bool
+	}{
+		{"/foo", true},
+		{"//foo", true},
+		{"foo", false},
+		{`c:\foo`, false},
+	}, func(input string) bool {
+		return NewPurePosixPath(input).IsAbs()
+	})
+}
+
+func TestPurePosixPath_FullMatch(t *testing.T) {
+	testcases := []struct {
+		input         string
+		pattern       string
+		caseSensitive bool
+		output        bool
+	}{
+		{"/foo/bar.txt", "/foo/*.txt", true, true},
+		{"/foo/bar.txt", "/FOO/*.TXT", true, false},
+		{"/foo/bar.txt", "/FOO/*.TXT", false, true},
+		{"/foo/bar/baz.txt", "/**/*.txt", true, true},
+		{"/foo/bar/baz.txt", "**/*.txt", true, false},
+		{"foo/a*b", `foo/a\*b`, true, true},
+		{"foo/axb", `foo/a\*b`, true, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.input+" matches "+tc.pattern, func(t *testing.T) {
+			result := NewPurePosixPath(tc.input).FullMatch(tc.pattern, tc.caseSensitive)
+			if result != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result)
+			}
+		})
+	}
+}
+
+func TestPurePosixPath_Match(t *testing.T) {
+	testcases := []struct {
+		input   string
+		pattern string
+		output  bool
+	}{
+		{"/foo/bar.txt", "bar.txt", true},
+		{"/foo/bar.txt", "BAR.TXT", false},
+		{"/foo/bar.txt", "foo/*.txt", true},
+		{"/foo/bar.txt", "/*.txt", false},
+		{"/foo/bar", ".", true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.input+" matches "+tc.pattern, func(t *testing.T) {
+			result := NewPurePosixPath(tc.input).Match(tc.pattern)
+			if result != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result)
+			}
+		})
+	}
+}
+
+func TestPurePosixPath_WithX(t *testing.T) {
+	testcases := []struct {
+		input  string
+		method string
+		arg    string
+		output string
+		err    error
+	}{
+		{"/foo/bar.txt", "name", "baz.md", "/foo/baz.md", nil},
+		{"/foo/bar.txt", "stem", "baz", "/foo/baz.txt", nil},
+		{"/foo/bar.txt", "suffix", ".md", "/foo/bar.md", nil},
+		{"/foo/bar.out.txt", "suffix", ".md", "/foo/bar.out.md", nil},
+		{"/foo/bar", "name", "a/b", "", px.ErrInvalidName},
+		{"/foo/bar", "name", "..", "", px.ErrInvalidName},
+		{"/foo/bar", "suffix", "md", "", px.ErrInvalidSuffix},
+		{"/", "name", "a", "", px.ErrNoName},
+		{"/foo/bar", "anchor", "//", "//foo/bar", nil},
+		{"/foo/bar", "anchor", "", "foo/bar", nil},
+		{"foo/bar", "anchor", "/", "/foo/bar", nil},
+		{"foo/bar", "anchor", "c:", "", px.ErrInvalidAnchor},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.input+" with "+tc.method+" "+tc.arg, func(t *testing.T) {
+			p := NewPurePosixPath(tc.input)
+			var result IPurePath
+			var err error
+			switch tc.method {
+			case "name":
+				result, err = p.WithName(tc.arg)
+			case "stem":
+				result, err = p.WithStem(tc.arg)
+			case "suffix":
+				result, err = p.WithSuffix(tc.arg)
+			case "anchor":
+				result, err = p.WithAnchor(tc.arg)
+			}
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Errorf("Expected error %v, got %v", tc.err, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			} else if result.String() != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result.String())
+			}
+		})
+	}
+}
+
+func TestPurePosixPath_RelTo(t *testing.T) {
+	testcases := []struct {
+		input  string
+		other  string
+		walkUp bool
+		output string
+		err    error
+	}{
+		{"/foo/bar.txt", "/foo", false, "bar.txt", nil},
+		{"/foo/bar.txt", "/FOO", false, "", px.ErrNotRelative},
+		{"/a/b", "/", false, "a/b", nil},
+		{"a/b", ".", false, "a/b", nil},
+		{"/a/b", "", false, "", px.ErrNotRelative},
+		{"/a/b", "/c", true, "../a/b", nil},
+		{"/foo/bar.txt", "/a/b", true, "../../foo/bar.txt", nil},
+		{"/foobar", "/foo", false, "", px.ErrNotRelative},
+		{"/foobar", "/foo", true, "../foobar", nil},
+		{"a/b", "a", false, "b", nil},
+		{"a/b", "c", true, "", px.ErrNotRelative}, // 相对路径不能向上遍历
+	}
+	for _, tc := range testcases {
+		t.Run(tc.input+" relative to "+tc.other, func(t *testing.T) {
+			result, err := NewPurePosixPath(tc.input).RelTo(NewPurePosixPath(tc.other), tc.walkUp)
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Errorf("Expected error %v, got %v", tc.err, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			} else if result.String() != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result.String())
+			}
+		})
+	}
+}
+
+func TestPurePosixPath_Validate(t *testing.T) {
+	testcases := []struct {
+		input  string
+		output error
+	}{
+		{"/foo/bar.txt", nil},
+		{"//foo", nil},
+		{`foo/c:\bar?`, nil},
+		{"/foo/ba\x00r", px.ErrInvalidName},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			err := NewPurePosixPath(tc.input).Validate()
+			if tc.output == nil && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			} else if tc.output != nil && !errors.Is(err, tc.output) {
+				t.Errorf("Expected error %v, got %v", tc.output, err)
+			}
+		})
+	}
+}
+
+func TestPurePosixPath_ToValid(t *testing.T) {
+	runTask(t, []struct {
+		input  string
+		output string
+	}{
+		{"/foo/ba\x00r", "/foo/ba_r"},
+		{"//foo/bar", "//foo/bar"},
+	}, func(input string) string {
+		return NewPurePosixPath(input).ToValid().String()
+	})
+}
+
+func TestPurePosixPath_ToWindows(t *testing.T) {
+	runTask(t, []struct {
+		input  string
+		output string
+	}{
+		{"/foo/bar", `\foo\bar`},
+		{"//server/share/file", `\\server\share\file`},
+		{"c:/foo", `c:\foo`},
+		{"foo/bar", `foo\bar`},
+		{".", "."},
+	}, func(input string) string {
+		p := NewPosix(input).ToWindows()
+		if p.Flavour() != FlavourWindows {
+			t.Errorf("ToWindows(%q).Flavour() = %v", input, p.Flavour())
+		}
+		return p.String()
+	})
+}
+
+func TestNewWithFlavour(t *testing.T) {
+	runTask(t, []struct {
+		input  Flavour
+		output string
+	}{
+		{FlavourWindows, `a\b`},
+		{FlavourPosix, "a/b"},
+	}, func(input Flavour) string {
+		p := NewWithFlavour(input, "a", "b")
+		if p.Flavour() != input || p.ToPosix().ToWindows().Flavour() != FlavourWindows {
+			t.Errorf("NewWithFlavour(%v) has flavour %v", input, p.Flavour())
+		}
+		return p.String()
+	})
+}
+
+func TestPurePosixPath_FromMountPath(t *testing.T) {
+	type input struct {
+		path      string
+		mountRoot string
+	}
+	runTask(t, []struct {
+		input  input
+		output string
+	}{
+		{input{"/mnt/c/Users/me", MountWSL}, `C:\Users\me`},
+		{input{"/cygdrive/c/Users/me", MountCygwin}, `C:\Users\me`},
+		{input{"/c/Users/me", MountMSYS}, `C:\Users\me`},
+		{input{"/mnt/d", MountWSL}, `D:\`},
+		{input{"/mnt/d/", MountWSL}, `D:\`},
+		{input{"//server/share/file", MountWSL}, `\\server\share\file`},
+		{input{"foo/bar", MountWSL}, `foo\bar`},
+		{input{"/mnt", MountWSL}, "error"},
+		{input{"/mnt/data/foo", MountWSL}, "error"},
+		{input{"/usr/bin", MountMSYS}, "error"},
+		{input{"/mntc/foo", MountWSL}, "error"},
+	}, func(in input) string {
+		winPath, err := NewPosix(in.path).FromMountPath(in.mountRoot)
+		if err != nil {
+			if !errors.Is(err, nt.ErrMountPath) {
+				t.Errorf("FromMountPath(%q, %q) returned unexpected error: %v", in.path, in.mountRoot, err)
+			}
+			return "error"
+		}
+		// 必须可以转换回原来的路径
+		back, err := winPath.ToMountPath(in.mountRoot)
+		if err != nil || back.String() != NewPosix(in.path).String() {
+			t.Errorf("ToMountPath(%q, %q) = %v, %v, want %q", winPath, in.mountRoot, back, err, in.path)
+		}
+		return winPath.String()
+	})
+}
+
+func TestPurePosixPath_Equal(t *testing.T) {
+	type input struct {
+		a, b IPurePath
+	}
+	runTask(t, []struct {
+		input  input
+		output int
+	}{
+		{input{NewPosix("/src/a"), NewPosix("/src//a/")}, 0},
+		{input{NewPosix("/Src"), NewPosix("/src")}, -1}, // 区分大小写
+		{input{NewPosix("/src"), NewPosix("/src/a")}, -1},
+		{input{NewPosix("a/b"), NewPosix("a.b")}, -1},
+		{input{NewPosix("a/b"), NewWindows(`a\b`)}, 1},
+		{input{NewPosix("a/b"), nil}, 1}, // nil排在所有路径之前
+	}, func(in input) int {
+		c := in.a.Compare(in.b)
+		if in.a.Equal(in.b) != (c == 0) {
+			t.Errorf("Equal(%q, %q) inconsistent with Compare %d", in.a, in.b, c)
+		}
+		return c
+	})
+}
+
+func TestSortPaths(t *testing.T) {
+	paths := []IPurePath{
+		NewWindows(`c:\src\b`), NewPosix("/b"), NewWindows(`C:\Src`), NewWindows(`c:\src`), NewPosix("/a/b"),
+	}
+	SortPaths(paths)
+	var result []string
+	for _, p := range paths {
+		result = append(result, p.String())
+	}
+	expected := []string{`C:\Src`, `c:\src`, `c:\src\b`, "/a/b", "/b"}
+	if !slices.Equal(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// nil排在最前面
+	withNil := []IPurePath{NewPosix("/a"), nil, NewWindows(`c:\a`)}
+	SortPaths(withNil)
+	if withNil[0] != nil {
+		t.Errorf("Expected nil to be sorted first, got %v", withNil)
+	}
+
+	// 可以使用Key去重
+	seen := map[string]bool{}
+	for _, p := range paths {
+		seen[p.Key()] = true
+	}
+	if len(seen) != 4 {
+		t.Errorf("Expected 4 unique keys, got %d", len(seen))
+	}
+}
+
+func TestPurePosixPath_CommonAncestor(t *testing.T) {
+	runTask(t, []struct {
+		input  []string
+		output string
+	}{
+		{[]string{"/src/a/b", "/src/a/c"}, "/src/a"},
+		{[]string{"/src/a", "/SRC/a"}, "/"},
+		{[]string{"/foo", "/foobar"}, "/"},
+		{[]string{"a/b", "a/b/c"}, "a/b"},
+		{[]string{"a", "/a"}, "error"},
+	}, func(input []string) string {
+		p := NewPosix(input[0])
+		var others []IPurePath
+		for _, other := range input[1:] {
+			others = append(others, NewPosix(other))
+		}
+		ancestor, err := p.CommonAncestor(others...)
+		if err != nil {
+			return "error"
+		}
+		if !ancestor.Equal(p) && !ancestor.IsAncestorOf(p) {
+			t.Errorf("%q is not an ancestor of %q", ancestor, p)
+		}
+		return ancestor.String()
+	})
+}
@@ -0,0 +1,202 @@
+package posixpath
+
+import (
+	"path"
+	"strings"
+
+	"github.com/viocha/go-pathlib/internal/common"
+)
+
+var (
+	ErrNoName        = common.ErrNoName
+	ErrNotRelative   = common.ErrNotRelative
+	ErrInvalidName   = common.ErrInvalidName
+	ErrInvalidAnchor = common.ErrInvalidAnchor
+	ErrInvalidSuffix = common.ErrInvalidSuffix
+)
+
+var (
+	InvalidChars = "/\x00"
+)
+
+// Parts 返回路径的各个部分，包括anchor部分，以及剩余的目录和名称部分。
+// anchor只可能是 / 或者 //，其中 // 的含义由具体实现定义，需要原样保留
+func Parts(p string) []string {
+	if p == "." {
+		return nil
+	}
+	p = Clean(p) // 先清理路径，确保是标准格式
+	if p == "." {
+		return nil
+	}
+
+	anchor := Anchor(p)
+	if len(p) == len(anchor) { // 只有根路径
+		return []string{anchor}
+	}
+	parts := strings.Split(p[len(anchor):], "/")
+	if anchor == "" {
+		return parts
+	}
+	return append([]string{anchor}, parts...)
+}
+
+// POSIX路径没有驱动器的概念，始终返回空字符串
+func Drive(p string) string {
+	return ""
+}
+
+// 返回根路径标识符，以 // 开头（但不是 ///）的路径根为 //，其他以 / 开头的路径根为 /，相对路径返回空字符串
+func Root(p string) string {
+	if strings.HasPrefix(p, "//") && !strings.HasPrefix(p, "///") {
+		return "//"
+	}
+	if strings.HasPrefix(p, "/") {
+		return "/"
+	}
+	return ""
+}
+
+func Anchor(p string) string {
+	return Drive(p) + Root(p) // 没有驱动器，anchor就是root
+}
+
+// 检查POSIX路径名是否合法，不支持空名称
+func ValidateName(name string) error {
+	if len(name) == 0 {
+		return common.WrapMsg(ErrNoName, "name cannot be empty")
+	}
+	if len(name) > 255 {
+		return common.WrapMsg(ErrInvalidName, "name %q exceeds maximum length of 255 bytes", name)
+	}
+	if name == "." || name == ".." {
+		return common.WrapMsg(ErrInvalidName, "name %q is a special directory name", name)
+	}
+
+	// 检查是否包含无效字符
+	for _, char := range InvalidChars {
+		if strings.ContainsRune(name, char) {
+			return common.WrapMsg(ErrInvalidName, "name %q contains invalid character %q", name, string(char))
+		}
+	}
+	return nil
+}
+
+func ValidateAnchor(anchor string) error {
+	if anchor != "" && anchor != "/" && anchor != "//" {
+		return common.WrapMsg(ErrInvalidAnchor, "invalid anchor %q", anchor)
+	}
+	return nil
+}
+
+// 检查路径是否合法，包括anchor和每个部分的名称
+func ValidatePath(p string) error {
+	p = Clean(p) // 先清理路径，确保是标准格式
+	anchor, parts := Anchor(p), Parts(p)
+
+	if anchor != "" {
+		if err := ValidateAnchor(anchor); err != nil {
+			return err
+		}
+		parts = parts[1:] // 去掉anchor部分
+	}
+
+	// 检查每个部分的名称是否合法
+	for _, part := range parts {
+		if err := ValidateName(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 让名称合法
+func ToValidName(name string) string {
+	if len(name) > 255 {
+		name = name[:255] // 截断到255个字节
+	}
+	// 替换无效字符
+	name = strings.NewReplacer("/", "_", "\x00", "_").Replace(name)
+	if name == "" || name == "." || name == ".." { // 空名称和特殊目录名称无法作为普通名称
+		return "_"
+	}
+	return name
+}
+
+// 规范化路径，并不会确保名称合法。开头恰好两个斜杠的情况会被保留
+func Clean(p string) string {
+	if p == "" {
+		return "."
+	}
+	cleaned := path.Clean(p)
+	if Root(p) == "//" {
+		if cleaned == "/" {
+			return "//"
+		}
+		return "/" + cleaned
+	}
+	return cleaned
+}
+
+// 连接两个路径，如果第二个路径是绝对路径，则覆盖前面的路径
+func Join(p, other string) string {
+	if strings.HasPrefix(other, "/") {
+		return Clean(other)
+	}
+	return Clean(p + "/" + other)
+}
+
+// 支持 ** 通配符的路径匹配函数，pattern和路径的anchor必须相同，** 不会匹配anchor部分
+func Match(pattern, p string) bool {
+	if Anchor(pattern) != Anchor(p) {
+		return false
+	}
+	patternParts := nameParts(pattern)
+	pathParts := nameParts(p)
+
+	// 初始化记忆化缓存
+	memo := make(map[[2]int]bool)
+	var match func(int, int) bool
+	match = func(patternIdx, pathIdx int) (res bool) {
+		key := [2]int{patternIdx, pathIdx}
+		if v, ok := memo[key]; ok {
+			return v
+		}
+		defer func() { memo[key] = res }()
+
+		if patternIdx == len(patternParts) {
+			return pathIdx == len(pathParts)
+		}
+		if patternParts[patternIdx] == "**" {
+			for currentPathPos := pathIdx; currentPathPos <= len(pathParts); currentPathPos++ {
+				if match(patternIdx+1, currentPathPos) {
+					return true
+				}
+			}
+			return false
+		}
+		if pathIdx >= len(pathParts) {
+			return false
+		}
+		if !MatchName(patternParts[patternIdx], pathParts[pathIdx]) {
+			return false
+		}
+		return match(patternIdx+1, pathIdx+1)
+	}
+	return match(0, 0)
+}
+
+// 返回去掉anchor之后的名称部分
+func nameParts(p string) []string {
+	parts := Parts(p)
+	if Anchor(p) != "" {
+		return parts[1:]
+	}
+	return parts
+}
+
+// 匹配单个名称，支持 * ? [] 以及使用 \ 转义
+func MatchName(pattern, name string) bool {
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
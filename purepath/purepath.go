@@ -2,12 +2,52 @@ package purepath
 
 import (
 	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/viocha/go-pathlib/internal/common"
+)
+
+// Flavour 路径风格，决定了分隔符、anchor的格式以及是否区分大小写
+type Flavour int
+
+const (
+	FlavourWindows Flavour = iota // Windows风格，使用 \ 分隔符，不区分大小写
+	FlavourPosix                  // POSIX风格，使用 / 分隔符，区分大小写
+)
+
+func (f Flavour) String() string {
+	switch f {
+	case FlavourWindows:
+		return "windows"
+	case FlavourPosix:
+		return "posix"
+	default:
+		return "unknown"
+	}
+}
+
+// 返回路径分隔符
+func (f Flavour) separator() string {
+	if f == FlavourWindows {
+		return `\`
+	}
+	return "/"
+}
+
+// 常用的盘符挂载根路径，用于 ToMountPath 和 FromMountPath
+const (
+	MountWSL    = "/mnt"      // WSL，C:\ 对应 /mnt/c
+	MountCygwin = "/cygdrive" // Cygwin，C:\ 对应 /cygdrive/c
+	MountMSYS   = "/"         // MSYS2 和 Git Bash，C:\ 对应 /c
 )
 
 // IBasePurePath 包含所有可以直接继承的方法，不依赖于特定的接口和类型
 type IBasePurePath interface {
 	String() string
-	ToPosix() string
+	AsPosix() string // 返回使用正斜杠的路径字符串
+	Flavour() Flavour
+	Key() string // 返回按照风格的大小写规则规范化的字符串，可以作为map的键，不同风格的键不能互相比较
 	Parts() []string
 
 	Root() string
@@ -24,6 +64,8 @@ type IBasePurePath interface {
 
 	FullMatch(pattern string, caseSensitive ...bool) bool
 	Match(pattern string, caseSensitive ...bool) bool
+	FullMatchPattern(pattern *Pattern) bool
+	MatchPattern(pattern *Pattern) bool
 }
 
 // IPurePath 纯路径接口，提供不实际访问文件系统的路径处理操作
@@ -33,6 +75,9 @@ type IBasePurePath interface {
 type IPurePath interface {
 	IBasePurePath
 
+	Equal(other IPurePath) bool  // 按照风格的大小写规则判断是否相等，不同风格的路径总是不相等
+	Compare(other IPurePath) int // 先比较风格，再按照风格的大小写规则逐个比较路径组件，返回 -1、0 或 1
+
 	Parents() []IPurePath
 	Parent() IPurePath
 	Join(segments ...string) IPurePath
@@ -47,6 +92,15 @@ type IPurePath interface {
 	WithSuffix(suffix string) (IPurePath, error)
 
 	ToValid() IPurePath
+	ToWindows() IPurePath // 转换成Windows风格的纯路径，已经是Windows风格时返回自身
+	ToPosix() IPurePath   // 转换成POSIX风格的纯路径，已经是POSIX风格时返回自身
+	// 将路径视为Windows路径，转换成盘符挂载在 mountRoot 下的POSIX路径，如 C:\Users 转换成 /mnt/c/Users
+	ToMountPath(mountRoot string) (IPurePath, error)
+	// 将路径视为盘符挂载在 mountRoot 下的POSIX路径，转换成Windows路径，如 /mnt/c/Users 转换成 C:\Users
+	FromMountPath(mountRoot string) (IPurePath, error)
+	IsAncestorOf(other IPurePath) bool                     // 是否是other的真祖先路径，路径本身不算作祖先
+	IsDescendantOf(other IPurePath) bool                   // 是否是other的真后代路径，路径本身不算作后代
+	CommonAncestor(others ...IPurePath) (IPurePath, error) // 最长公共祖先路径，没有时返回错误
 	IsRelTo(other IPurePath, walkUp ...bool) bool
 	RelTo(other IPurePath, walkUp ...bool) (IPurePath, error)
 	RelToFile(other IPurePath, walkUp ...bool) (IPurePath, error)
@@ -59,11 +113,140 @@ type IPurePath interface {
 	MustWithSuffix(suffix string) IPurePath
 	MustRelTo(other IPurePath, walkUp ...bool) IPurePath
 	MustRelToFile(other IPurePath, walkUp ...bool) IPurePath
+	MustCommonAncestor(others ...IPurePath) IPurePath
+	MustToMountPath(mountRoot string) IPurePath
+	MustFromMountPath(mountRoot string) IPurePath
 }
 
+// 根据当前操作系统创建纯路径对象，Windows上使用 PureWindowsPath，其他系统使用 PurePosixPath
 func New(segments ...string) IPurePath {
 	if runtime.GOOS == "windows" {
-		return NewPureWindowsPath(segments...)
+		return NewWindows(segments...)
+	}
+	return NewPosix(segments...)
+}
+
+// 创建Windows风格的纯路径对象，在任何操作系统上都可以使用
+func NewWindows(segments ...string) IPurePath {
+	return NewPureWindowsPath(segments...)
+}
+
+// 创建POSIX风格的纯路径对象，在任何操作系统上都可以使用
+func NewPosix(segments ...string) IPurePath {
+	return NewPurePosixPath(segments...)
+}
+
+// 创建指定风格的纯路径对象
+func NewWithFlavour(flavour Flavour, segments ...string) IPurePath {
+	if flavour == FlavourWindows {
+		return NewWindows(segments...)
+	}
+	return NewPosix(segments...)
+}
+
+// 按照 Compare 的规则对路径进行稳定排序，相等的路径保持原有的顺序
+func SortPaths(paths []IPurePath) {
+	slices.SortStableFunc(paths, func(a, b IPurePath) int {
+		if a == nil { // nil排在最前面
+			if b == nil {
+				return 0
+			}
+			return -1
+		}
+		return a.Compare(b)
+	})
+}
+
+// 返回所有路径的最长公共路径，路径的风格必须相同，并且必须都是绝对路径或者都是相对路径
+func CommonPath(paths ...IPurePath) (IPurePath, error) {
+	if len(paths) == 0 {
+		return nil, common.WrapMsg(common.ErrNotRelative, "no paths to find common path")
+	}
+	return paths[0].CommonAncestor(paths[1:]...)
+}
+
+func MustCommonPath(paths ...IPurePath) IPurePath {
+	path, err := CommonPath(paths...)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// 返回用于比较的路径组件，按照风格的大小写规则规范化
+func keyParts(p IPurePath) []string {
+	return NewWithFlavour(p.Flavour(), p.Key()).Parts()
+}
+
+// 返回两组路径组件的公共前缀长度
+func commonPrefixLen(parts, otherParts []string) int {
+	n := 0
+	for n < len(parts) && n < len(otherParts) && parts[n] == otherParts[n] {
+		n++
+	}
+	return n
+}
+
+// p是否是other的真祖先路径，anchor也是路径组件之一，所以 / 是 /a 的祖先，. 是所有非空相对路径的祖先
+func isAncestor(p, other IPurePath) bool {
+	if p.Flavour() != other.Flavour() {
+		return false
+	}
+	parts, otherParts := keyParts(p), keyParts(other)
+	return len(parts) < len(otherParts) && commonPrefixLen(parts, otherParts) == len(parts)
+}
+
+// 逐个比较路径组件，找到最长公共祖先路径，保留p的大小写
+func commonAncestor(p IPurePath, others []IPurePath) (IPurePath, error) {
+	parts := keyParts(p)
+	n := len(parts)
+	for _, other := range others {
+		if other.Flavour() != p.Flavour() {
+			return nil, common.WrapMsg(common.ErrNotRelative, "paths %q and %q have different flavours", p, other)
+		}
+		n = commonPrefixLen(parts[:n], keyParts(other))
+		if n == 0 && (p.Anchor() != "" || other.Anchor() != "") { // anchor不同，没有公共祖先
+			return nil, common.WrapMsg(common.ErrNotRelative, "paths %q and %q have no common ancestor", p, other)
+		}
+	}
+	if n == len(parts) {
+		return p, nil
+	}
+	parents := p.Parents() // 从近到远排列，最后一个是anchor或者 .
+	return parents[len(parts)-n-1], nil
+}
+
+// p是否相对于other，允许向上遍历时只需要存在公共祖先，但相对路径不能向上遍历，因为无法知道上级目录的名称
+func isRelTo(p, other IPurePath, walkUp bool) bool {
+	if p.Equal(other) || other.IsAncestorOf(p) {
+		return true
+	}
+	if !walkUp || p.Anchor() == "" {
+		return false
+	}
+	_, err := p.CommonAncestor(other)
+	return err == nil
+}
+
+// 计算p相对于other的路径，先回退到公共祖先，再进入p剩余的部分
+func relTo(p, other IPurePath, walkUp bool) (IPurePath, error) {
+	if !isRelTo(p, other, walkUp) {
+		return nil, common.WrapMsg(common.ErrNotRelative, "path %q is not relative to %q", p, other)
+	}
+	ancestor, err := p.CommonAncestor(other)
+	if err != nil {
+		return nil, err
+	}
+	n := len(ancestor.Parts())
+
+	var segments []string
+	for range len(other.Parts()) - n {
+		segments = append(segments, "..") // 向上遍历的部分
+	}
+	segments = append(segments, p.Parts()[n:]...)
+	if len(segments) == 0 {
+		return NewWithFlavour(p.Flavour(), "."), nil
 	}
-	panic("purepath package is not implemented for this OS")
+	// 使用分隔符连接，而不是逐个连接，避免 a:b 这样的名称被误认为盘符
+	return NewWithFlavour(p.Flavour(), strings.Join(segments, p.Flavour().separator())), nil
 }
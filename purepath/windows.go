@@ -1,7 +1,7 @@
 package purepath
 
 import (
-	"path/filepath"
+	"cmp"
 	"slices"
 	"strings"
 
@@ -16,44 +16,16 @@ type PureWindowsPath struct {
 
 var _ IPurePath = (*PureWindowsPath)(nil) // 确保实现了IPurePath接口
 
-// 创建新的Windows纯路径对象
+// 创建新的Windows纯路径对象，解析规则和当前操作系统无关
 func NewPureWindowsPath(segments ...string) *PureWindowsPath {
 	if len(segments) == 0 {
 		return &PureWindowsPath{path: "."}
 	}
-	if len(segments) == 1 && (segments[0] == "" || segments[0] == ".") {
-		return &PureWindowsPath{path: "."}
-	}
 
 	path := nt.Clean(segments[0])
 	for _, seg := range segments[1:] {
-		seg = nt.Clean(seg)               // 规范化每个路径段
-		if strings.HasPrefix(seg, `\\`) { // UNC绝对路径，覆盖前面的路径
-			path = seg
-		} else if nt.RegDrive.MatchString(seg) { // 以盘符开头的路径
-			drive := seg[:2]                     // 提取盘符
-			if len(seg) >= 3 && seg[2] == '\\' { // 有根路径\，为绝对路径
-				path = seg // 直接覆盖
-			} else { // 为相对路径，需要考虑是否和前面的盘符相同
-				if strings.HasPrefix(path, drive) { // 盘符相同，则附加
-					path = filepath.Join(path, seg[2:]) // 去掉盘符后的路径
-				} else { // 盘符不同，则覆盖
-					path = seg // 直接覆盖
-				}
-			}
-		} else if strings.HasPrefix(seg, `\`) { // 以\开头的绝对路径
-			// 提取前面的盘符（带有反斜杠）
-			if nt.RegDriveRoot.MatchString(path) {
-				path = path[:2] + seg // 盘符+绝对路径
-			} else {
-				path = seg // 没有盘符，则直接覆盖
-			}
-		} else { // 常规的相对路径
-			path = filepath.Join(path, seg) // 注意 .. 也会被解析，和python的不同
-		}
+		path = nt.Join(path, seg) // 绝对路径覆盖前面的路径，注意 .. 也会被解析，和python的不同
 	}
-
-	path = nt.Clean(path) // 规范化路径
 	return &PureWindowsPath{path: path}
 }
 
@@ -121,8 +93,20 @@ func (p *PureWindowsPath) Parents() []IPurePath {
 	return result
 }
 
-// 返回最后一个路径组件
+// 返回最后一个路径组件，不包含NTFS备用数据流部分
 func (p *PureWindowsPath) Name() string {
+	name, _ := nt.SplitStream(p.fullName())
+	return name
+}
+
+// 返回NTFS备用数据流部分，如 report.docx:Zone.Identifier:$DATA 返回 Zone.Identifier:$DATA，没有数据流时返回空字符串
+func (p *PureWindowsPath) Stream() string {
+	_, stream := nt.SplitStream(p.fullName())
+	return stream
+}
+
+// 返回包含数据流的最后一个路径组件
+func (p *PureWindowsPath) fullName() string {
 	parts := p.Parts()
 	if len(parts) == 0 {
 		return ""
@@ -200,7 +184,41 @@ func (p *PureWindowsPath) WithName(name string) (IPurePath, error) {
 	if err := nt.ValidateName(name); err != nil {
 		return nil, err
 	}
-	return NewPureWindowsPath(p.path, "..", name), nil
+	return p.replaceName(name), nil
+}
+
+// 返回修改NTFS备用数据流后的新路径，stream的格式为 name[:type]，空字符串表示去掉数据流
+func (p *PureWindowsPath) WithStream(stream string) (IPurePath, error) {
+	name := p.Name()
+	if name == "" {
+		return nil, common.WrapMsg(nt.ErrNoName, "cannot set stream %q on path without name", stream)
+	}
+	if err := nt.ValidateStream(stream); err != nil {
+		return nil, err
+	}
+	if stream != "" {
+		name += ":" + stream
+	}
+	return p.replaceFullName(name), nil
+}
+
+// 替换最后一个路径组件的名称，保留原有的数据流
+func (p *PureWindowsPath) replaceName(name string) IPurePath {
+	if stream := p.Stream(); stream != "" {
+		name += ":" + stream
+	}
+	return p.replaceFullName(name)
+}
+
+// 替换包含数据流的最后一个路径组件。不使用 .. 回退，因为扩展长度路径中的 .. 会原样保留，
+// 也不逐个连接各部分，避免 a:stream 这样的名称被误认为盘符
+func (p *PureWindowsPath) replaceFullName(fullName string) IPurePath {
+	anchor, parts := p.Anchor(), p.Parts()
+	parts[len(parts)-1] = fullName
+	if anchor != "" {
+		parts = parts[1:]
+	}
+	return NewPureWindowsPath(anchor + strings.Join(parts, `\`))
 }
 
 // 返回修改父路径后的新路径，parent必须是一个有效的IPurePath对象
@@ -209,7 +227,7 @@ func (p *PureWindowsPath) WithParent(parent IPurePath) (IPurePath, error) {
 	if name == "" {
 		return nil, common.WrapMsg(nt.ErrNoName, "cannot set parent on path without name")
 	}
-	return parent.Join(name), nil
+	return parent.Join(p.fullName()), nil
 }
 
 // 返回修改stem后的新路径
@@ -224,7 +242,7 @@ func (p *PureWindowsPath) WithStem(stem string) (IPurePath, error) {
 	if err := nt.ValidateName(newName); err != nil {
 		return nil, err
 	}
-	return NewPureWindowsPath(p.path, "..", newName), nil
+	return p.replaceName(newName), nil
 }
 
 // 返回修改suffix后的新路径，必须存在文件名，否则返回ErrNoName错误，suffix必须以点开头，否则返回ErrInvalidSuffix错误
@@ -249,14 +267,81 @@ func (p *PureWindowsPath) WithSuffix(suffix string) (IPurePath, error) {
 	if err := nt.ValidateName(newName); err != nil {
 		return nil, err
 	}
-	return NewPureWindowsPath(p.path, "..", newName), nil
+	return p.replaceName(newName), nil
 }
 
 // 返回使用正斜杠的路径字符串
-func (p *PureWindowsPath) ToPosix() string {
+func (p *PureWindowsPath) AsPosix() string {
 	return strings.ReplaceAll(p.path, `\`, `/`)
 }
 
+func (p *PureWindowsPath) Flavour() Flavour {
+	return FlavourWindows
+}
+
+// 返回转换成小写的路径，Windows路径不区分大小写，包括盘符和UNC服务器名
+func (p *PureWindowsPath) Key() string {
+	return strings.ToLower(p.path)
+}
+
+// 不区分大小写判断路径是否相等，如 C:\Src 和 c:\src 相等
+func (p *PureWindowsPath) Equal(other IPurePath) bool {
+	return other != nil && other.Flavour() == FlavourWindows && p.Key() == other.Key()
+}
+
+// 先比较风格，再不区分大小写逐个比较路径组件，nil排在所有路径之前
+func (p *PureWindowsPath) Compare(other IPurePath) int {
+	if other == nil {
+		return 1
+	}
+	if c := cmp.Compare(p.Flavour(), other.Flavour()); c != 0 {
+		return c
+	}
+	return slices.Compare(nt.Parts(p.Key()), nt.Parts(other.Key()))
+}
+
+func (p *PureWindowsPath) ToWindows() IPurePath {
+	return p
+}
+
+// 转换成POSIX风格的路径，盘符会成为普通的名称，如 c:\foo 转换成 c:/foo，UNC路径转换成以 // 开头的路径
+func (p *PureWindowsPath) ToPosix() IPurePath {
+	return NewPurePosixPath(p.AsPosix())
+}
+
+// 转换成盘符挂载在 mountRoot 下的POSIX路径，UNC路径转换成 //server/share 形式
+func (p *PureWindowsPath) ToMountPath(mountRoot string) (IPurePath, error) {
+	path, err := nt.ToMountPath(p.path, mountRoot)
+	if err != nil {
+		return nil, err
+	}
+	return NewPurePosixPath(path), nil
+}
+
+// 先使用正斜杠转换成POSIX路径，再转换成Windows路径，如 \mnt\c\foo 转换成 C:\foo
+func (p *PureWindowsPath) FromMountPath(mountRoot string) (IPurePath, error) {
+	return p.ToPosix().FromMountPath(mountRoot)
+}
+
+// 转换成扩展长度路径，如 C:\foo 转换成 \\?\C:\foo，\\server\share 转换成 \\?\UNC\server\share，
+// 可以突破 260 个字符的长度限制，只支持绝对路径
+func (p *PureWindowsPath) ToExtendedLength() (IPurePath, error) {
+	path, err := nt.ToExtendedLength(p.path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPureWindowsPath(path), nil
+}
+
+// 去掉扩展长度路径的前缀 \\?\，转换成普通路径，不是扩展长度路径时原样返回
+func (p *PureWindowsPath) FromExtendedLength() (IPurePath, error) {
+	path, err := nt.FromExtendedLength(p.path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPureWindowsPath(path), nil
+}
+
 // 返回此路径是否为绝对路径
 func (p *PureWindowsPath) IsAbs() bool {
 	return p.Drive() != "" && p.Root() != "" // 有盘符和根路径，才是绝对路径
@@ -264,40 +349,46 @@ func (p *PureWindowsPath) IsAbs() bool {
 
 // 返回此路径是否相对于other路径，walkUp参数表示是否允许向上遍历
 func (p *PureWindowsPath) IsRelTo(other IPurePath, walkUp ...bool) bool {
-	isWalkUp := common.ParseOptional(walkUp, true) // 默认允许向上遍历，和python不同
-	// 忽略大小写比较
-	pPath := strings.ToLower(p.String())
-	otherPath := strings.ToLower(other.String())
-	pAnchor := strings.ToLower(p.Anchor())
-	otherAnchor := strings.ToLower(other.Anchor())
-	if pAnchor != "" {
-		if isWalkUp {
-			return pAnchor == otherAnchor // 允许向上遍历到根路径
-		}
-		return strings.HasPrefix(pPath, otherPath) && pAnchor == otherAnchor
-	} else {
-		if otherAnchor != "" {
-			return false
-		}
-		return otherPath == "." || strings.HasPrefix(pPath, otherPath) // 无根路径，必须是前缀
-	}
+	return isRelTo(p, other, common.ParseOptional(walkUp, true)) // 默认允许向上遍历，和python不同
+}
+
+// 是否是other的真祖先路径，逐个比较路径组件，路径本身不算作祖先
+func (p *PureWindowsPath) IsAncestorOf(other IPurePath) bool {
+	return isAncestor(p, other)
+}
+
+// 是否是other的真后代路径，逐个比较路径组件，路径本身不算作后代
+func (p *PureWindowsPath) IsDescendantOf(other IPurePath) bool {
+	return isAncestor(other, p)
+}
+
+// 返回此路径和others的最长公共祖先路径，可能是路径本身，没有公共祖先时返回错误
+func (p *PureWindowsPath) CommonAncestor(others ...IPurePath) (IPurePath, error) {
+	return commonAncestor(p, others)
 }
 
 func (p *PureWindowsPath) Validate() error {
 	return nt.ValidatePath(p.path)
 }
 
-// 让所有名称都变得合法，不会改变anchor部分。如果anchor部分不合法，则结果路径会偏差很大
+// 让所有名称都变得合法，不会改变anchor部分。如果anchor部分不合法，则结果路径会偏差很大。
+// 合法的数据流会被保留，不合法的数据流会被去掉
 func (p *PureWindowsPath) ToValid() IPurePath {
 	anchor, parts := p.Anchor(), p.Parts()
 	if anchor != "" {
 		parts = parts[1:] // 去掉anchor部分
 	}
 	for i, part := range parts {
+		stream := ""
+		if i == len(parts)-1 { // 只有最后一个部分可以带有数据流
+			part, stream = nt.SplitStream(part)
+		}
 		parts[i] = nt.ToValidName(part) // 让每个部分的名称合法
+		if stream != "" && nt.ValidateStream(stream) == nil {
+			parts[i] += ":" + stream
+		}
 	}
-	parts = slices.Insert(parts, 0, anchor) // 将anchor部分重新插入到最前面
-	return NewPureWindowsPath(parts...)     // 重新组合路径
+	return NewPureWindowsPath(anchor + strings.Join(parts, `\`)) // 重新组合路径，anchor中已经包含了分隔符
 }
 
 // 将路径与给定的路径段组合
@@ -322,77 +413,32 @@ func (p *PureWindowsPath) JoinPathForFile(path IPurePath) IPurePath {
 	return p.Parent().JoinPath(path) // 使用父路径进行组合
 }
 
-// 将此路径与pattern完全匹配
+// 将此路径与pattern完全匹配，默认不区分大小写，支持的语法见 Pattern，不合法的模式不会匹配任何路径。
+// 需要使用同一个模式匹配大量路径时，应该先使用 CompilePattern 编译，然后调用 FullMatchPattern
 func (p *PureWindowsPath) FullMatch(pattern string, caseSensitive ...bool) bool {
-	cs := common.ParseOptional(caseSensitive, false) // 默认不区分大小写
-
-	path := p.String()
-	if !cs { // 如果不区分大小写，则转换为小写
-		path = strings.ToLower(path)
-		pattern = strings.ToLower(pattern)
-	}
+	compiled, err := compileMatchPattern(FlavourWindows, pattern, caseSensitive)
+	return err == nil && compiled.FullMatch(p)
+}
 
-	// 规范化模式串格式
-	pattern = nt.Clean(pattern)
-	// 支持**语法的匹配方式
-	return nt.Match(pattern, path)
+// 将此路径与编译后的模式完全匹配
+func (p *PureWindowsPath) FullMatchPattern(pattern *Pattern) bool {
+	return pattern.FullMatch(p)
 }
 
 // 将此路径与pattern匹配，如果pattern是相对路径，则从右侧开始匹配
 func (p *PureWindowsPath) Match(pattern string, caseSensitive ...bool) bool {
-	cs := common.ParseOptional(caseSensitive, false) // 默认不区分大小写
-
-	path := p.String()
-	if !cs { // 如果不区分大小写，则转换为小写
-		path = strings.ToLower(path)
-		pattern = strings.ToLower(pattern)
-	}
+	compiled, err := compileMatchPattern(FlavourWindows, pattern, caseSensitive)
+	return err == nil && compiled.Match(p)
+}
 
-	patternPath := NewPureWindowsPath(pattern)
-	if patternPath.IsAbs() || patternPath.Drive() != "" { // 如果模式是绝对路径或包含盘符
-		return p.FullMatch(pattern, caseSensitive...) // 使用全匹配
-	}
-	// 相对路径从右侧开始匹配
-	parts := p.Parts()
-	patternParts := patternPath.Parts()
-	if len(parts) < len(patternParts) {
-		return false // 如果当前路径部分少于模式部分，无法匹配
-	}
-	for i := len(patternParts) - 1; i >= 0; i-- {
-		matched, _ := filepath.Match(patternParts[i], parts[len(parts)-1-i])
-		if !matched {
-			return false // 如果任意部分不匹配，则返回false
-		}
-	}
-	return true // 如果所有模式部分都匹配，则返回true
+// 将此路径与编译后的模式匹配，如果模式是相对路径，则从右侧开始匹配
+func (p *PureWindowsPath) MatchPattern(pattern *Pattern) bool {
+	return pattern.Match(p)
 }
 
 // 计算此路径相对于other的版本
 func (p *PureWindowsPath) RelTo(other IPurePath, walkUp ...bool) (IPurePath, error) {
-	isWalkUp := common.ParseOptional(walkUp, true) // 默认允许向上遍历
-	if !p.IsRelTo(other, isWalkUp) {
-		return nil, common.WrapMsg(nt.ErrNotRelative, "path %q is not relative to %q", p, other)
-	}
-	parts, otherParts := p.Parts(), other.Parts()
-	// 找到公共前缀长度
-	commonLength := 0
-	for i := 0; i < len(otherParts); i++ {
-		if !strings.EqualFold(parts[i], otherParts[i]) {
-			break
-		}
-		commonLength++
-	}
-	if !isWalkUp {
-		return NewPureWindowsPath(parts[commonLength:]...), nil
-	}
-	// 计算向上遍历的次数
-	upLevels := len(otherParts) - commonLength
-	upSegments := make([]string, upLevels)
-	for i := 0; i < upLevels; i++ {
-		upSegments[i] = ".." // 向上遍历的部分
-	}
-	newParts := append(upSegments, parts[commonLength:]...) // 合并向上遍历的部分和剩余部分
-	return NewPureWindowsPath(newParts...), nil
+	return relTo(p, other, common.ParseOptional(walkUp, true)) // 默认允许向上遍历
 }
 
 // 基于目标文件的相对路径，会先获取目标文件的父路径，然后计算相对路径
@@ -455,3 +501,51 @@ func (p *PureWindowsPath) MustRelToFile(other IPurePath, walkUp ...bool) IPurePa
 	}
 	return path
 }
+
+func (p *PureWindowsPath) MustToMountPath(mountRoot string) IPurePath {
+	path, err := p.ToMountPath(mountRoot)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PureWindowsPath) MustFromMountPath(mountRoot string) IPurePath {
+	path, err := p.FromMountPath(mountRoot)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PureWindowsPath) MustToExtendedLength() IPurePath {
+	path, err := p.ToExtendedLength()
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PureWindowsPath) MustFromExtendedLength() IPurePath {
+	path, err := p.FromExtendedLength()
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PureWindowsPath) MustWithStream(stream string) IPurePath {
+	path, err := p.WithStream(stream)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *PureWindowsPath) MustCommonAncestor(others ...IPurePath) IPurePath {
+	path, err := p.CommonAncestor(others...)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
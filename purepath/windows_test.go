@@ -75,6 +75,15 @@ func TestPureWindowsPath_Parts(t *testing.T) {
 		{"c:foo/bar/", []string{`c:`, "foo", "bar"}},
 		{"/foo/bar/", []string{`\`, "foo", "bar"}},
 		{"foo/bar/", []string{"foo", "bar"}},
+		// 反斜杠在所有操作系统上都是分隔符
+		{`C:\build\out\*.dll`, []string{`C:\`, "build", "out", "*.dll"}},
+		{`\\server\share\dir\..\file`, []string{`\\server\share\`, "file"}},
+		// 扩展长度路径和设备路径
+		{`\\?\C:\very\long`, []string{`\\?\C:\`, "very", "long"}},
+		{`\\?\UNC\server\share\x`, []string{`\\?\UNC\server\share\`, "x"}},
+		{`\\.\PhysicalDrive0`, []string{`\\.\PhysicalDrive0\`}},
+		{`\\?\Volume{b75e2c83-0000-0000-0000-602f00000000}\dir`, []string{`\\?\Volume{b75e2c83-0000-0000-0000-602f00000000}\`, "dir"}},
+		{`\\?\C:\a\.\..\b`, []string{`\\?\C:\`, "a", ".", "..", "b"}},
 	}, func(input string) []string {
 		return NewPureWindowsPath(input).Parts()
 	})
@@ -91,6 +100,10 @@ func TestPureWindowsPath_Drive(t *testing.T) {
 		{"/foo/bar", ""},
 		{"foo/bar", ""},
 		{"", ""},
+		{`\\?\C:\very\long`, `\\?\C:`},
+		{`\\?\UNC\server\share\x`, `\\?\UNC\server\share`},
+		{`\\.\PhysicalDrive0`, `\\.\PhysicalDrive0`},
+		{`\\?\Volume{GUID}\`, `\\?\Volume{GUID}`},
 	}, func(input string) string {
 		return NewPureWindowsPath(input).Drive()
 	})
@@ -125,6 +138,9 @@ func TestPureWindowsPath_Anchor(t *testing.T) {
 		{"/foo/bar", `\`},
 		{"foo/bar", ""},
 		{"", ""},
+		{`\\?\C:\very\long`, `\\?\C:\`},
+		{`\\?\UNC\server\share\x`, `\\?\UNC\server\share\`},
+		{`\\.\PhysicalDrive0`, `\\.\PhysicalDrive0\`},
 	}, func(input string) string {
 		return NewPureWindowsPath(input).Anchor()
 	})
@@ -289,7 +305,27 @@ func TestPureWindowsPath_AsPosix(t *testing.T) {
 		{".", "."},
 		{"", "."},
 	}, func(input string) string {
-		return NewPureWindowsPath(input).ToPosix()
+		return NewPureWindowsPath(input).AsPosix()
+	})
+}
+
+func TestPureWindowsPath_ToPosix(t *testing.T) {
+	runTask(t, []struct {
+		input  string
+		output string
+	}{
+		{`c:\foo\bar`, "c:/foo/bar"},
+		{`\\server\share\file`, "//server/share/file"},
+		{`\\server\share\`, "//server/share"},
+		{`\foo\bar`, "/foo/bar"},
+		{`foo\bar`, "foo/bar"},
+		{".", "."},
+	}, func(input string) string {
+		p := NewWindows(input).ToPosix()
+		if p.Flavour() != FlavourPosix {
+			t.Errorf("ToPosix(%q).Flavour() = %v", input, p.Flavour())
+		}
+		return p.String()
 	})
 }
 
@@ -306,6 +342,9 @@ func TestPureWindowsPath_IsAbsolute(t *testing.T) {
 		{"foo/bar", false},
 		{".", false},
 		{"", false},
+		{`\\?\C:\very\long`, true},
+		{`\\?\UNC\server\share\x`, true},
+		{`\\.\PhysicalDrive0`, true},
 	}, func(input string) bool {
 		return NewPureWindowsPath(input).IsAbs()
 	})
@@ -380,6 +419,8 @@ func TestPureWindowsPath_FullMatch(t *testing.T) {
 		{"c:/foo/bar.txt", "c:/**", false, true},
 		{"foo", "./foo", false, true},
 		{"foo/bar", "bar", false, false},
+		{`C:\build\out\app.dll`, `C:\build\out\*.dll`, false, true},
+		{`C:\build\out\sub\app.dll`, `c:\BUILD\**\*.DLL`, false, true},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.input+" matches "+tc.pattern, func(t *testing.T) {
@@ -407,6 +448,8 @@ func TestPureWindowsPath_Match(t *testing.T) {
 		{"/foo/bar", "a/../bar", false, true},
 		{"/foo/bar", ".", false, true},
 		{"/foo/BAR", "bar", true, false},
+		{`C:\build\out\app.dll`, `out\*.dll`, false, true},
+		{`C:\build\out\app.dll`, `build\*.dll`, false, false},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.input+" matches "+tc.pattern, func(t *testing.T) {
@@ -539,6 +582,10 @@ func TestPureWindowsPath_RelativeTo(t *testing.T) {
 		{"a/b", "c", true, ``, nt.ErrNotRelative},
 		{"/a/b", "/c", true, `..\a\b`, nil},
 		{"c:/foo/bar.txt", "c:/a/b", true, `..\..\foo\bar.txt`, nil},
+		{"c:/foobar", "c:/foo", false, ``, nt.ErrNotRelative}, // 逐个比较路径组件，而不是字符串前缀
+		{"c:/foobar", "c:/foo", true, `..\foobar`, nil},
+		{"C:/Foo/bar", "c:/foo", false, `bar`, nil},
+		{"c:/foo", "d:/foo", true, ``, nt.ErrNotRelative},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.input+" relative to "+tc.other, func(t *testing.T) {
@@ -612,9 +659,18 @@ func TestPureWindowsPath_ValidatePath(t *testing.T) {
 		{"/", nil},
 		{"c:", nil},
 		{"//server/share/", nil},
+		{`\\?\C:\very\long.txt`, nil},
+		{`\\?\UNC\server\share\file.txt`, nil},
+		{`\\.\PhysicalDrive0`, nil},
 
-		{"c:/foo/invalid:name.txt", nt.ErrInvalidName}, // 包含非法字符
-		{"c:/foo/bar/na\rme", nt.ErrInvalidName},       // 包含回车符
+		{"c:/foo/invalid:name/bar.txt", nt.ErrInvalidName}, // 包含非法字符
+		{"c:/foo/bar.txt:Zone.Identifier", nil},            // 备用数据流
+		{"c:/foo/bar.txt:Zone.Identifier:$DATA", nil},
+		{"c:/foo/bar.txt::$DATA", nil},
+		{"c:/foo/bar.txt:Zone.Identifier:DATA", nt.ErrInvalidStream},
+		{"c:/foo/bar.txt:", nt.ErrInvalidStream},
+		{"c:/foo/con:stream", nt.ErrInvalidName},
+		{"c:/foo/bar/na\rme", nt.ErrInvalidName}, // 包含回车符
 		{"c:/foo/bar/na\rme\t", nt.ErrInvalidName},
 		{"c:/foo/bar/con", nt.ErrInvalidName},
 	}
@@ -644,7 +700,9 @@ func TestPureWindowsPath_ToValid(t *testing.T) {
 		{"c:/foo/bar\rtxt", `c:\foo\bar txt`},
 		{"c:/foo/bar\ntxt", `c:\foo\bar txt`},
 		{"c:/foo/bar\t", `c:\foo\bar`},
-		{"c:/foo/bar:txt", `c:\foo\bar_txt`},
+		{"c:/foo:txt/bar", `c:\foo_txt\bar`},
+		{"c:/foo/bar?:s", `c:\foo\bar_:s`}, // 合法的数据流会被保留
+		{"c:/foo/bar:s:x", `c:\foo\bar`},   // 不合法的数据流会被去掉
 		{"c:/foo/bar?txt", `c:\foo\bar_txt`},
 		{"c:/foo/bar\"txt", `c:\foo\bar_txt`},
 	}
@@ -659,3 +717,239 @@ func TestPureWindowsPath_ToValid(t *testing.T) {
 		})
 	}
 }
+
+func TestPureWindowsPath_ToMountPath(t *testing.T) {
+	type input struct {
+		path      string
+		mountRoot string
+	}
+	runTask(t, []struct {
+		input  input
+		output string
+	}{
+		{input{`C:\Users\me`, MountWSL}, "/mnt/c/Users/me"},
+		{input{`C:\Users\me`, MountCygwin}, "/cygdrive/c/Users/me"},
+		{input{`C:\Users\me`, MountMSYS}, "/c/Users/me"},
+		{input{`D:\`, MountWSL}, "/mnt/d"},
+		{input{`D:\`, MountMSYS}, "/d"},
+		{input{`E:\data`, "/media/"}, "/media/e/data"},
+		{input{`\\server\share\dir\file`, MountWSL}, "//server/share/dir/file"},
+		{input{`\\server\share\`, MountWSL}, "//server/share"},
+		{input{`foo\bar`, MountWSL}, "foo/bar"},
+		{input{`c:foo`, MountWSL}, "error"},
+		{input{`\foo`, MountWSL}, "error"},
+		{input{`\\?\C:\foo`, MountWSL}, "error"},
+		{input{`C:\foo`, "mnt"}, "error"},
+		{input{`C:\foo`, "//mnt"}, "error"},
+	}, func(in input) string {
+		mountPath, err := NewWindows(in.path).ToMountPath(in.mountRoot)
+		if err != nil {
+			if !errors.Is(err, nt.ErrMountPath) {
+				t.Errorf("ToMountPath(%q, %q) returned unexpected error: %v", in.path, in.mountRoot, err)
+			}
+			return "error"
+		}
+		// 必须可以转换回原来的路径
+		back, err := mountPath.FromMountPath(in.mountRoot)
+		if err != nil || back.String() != NewWindows(in.path).String() {
+			t.Errorf("FromMountPath(%q, %q) = %v, %v, want %q", mountPath, in.mountRoot, back, err, in.path)
+		}
+		return mountPath.String()
+	})
+}
+
+func TestPureWindowsPath_ExtendedLength(t *testing.T) {
+	runTask(t, []struct {
+		input  string
+		output string
+	}{
+		{`C:\very\long`, `\\?\C:\very\long`},
+		{`c:/a/../b`, `\\?\c:\b`},
+		{`C:\`, `\\?\C:\`},
+		{`\\server\share\x`, `\\?\UNC\server\share\x`},
+		{`\\server\share`, `\\?\UNC\server\share\`},
+		{`\\?\C:\x`, `\\?\C:\x`},
+		{`\\.\PhysicalDrive0`, `\\.\PhysicalDrive0\`},
+		{`foo\bar`, "error"},
+		{`c:foo`, "error"},
+		{`\foo`, "error"},
+	}, func(input string) string {
+		extended, err := NewPureWindowsPath(input).ToExtendedLength()
+		if err != nil {
+			if !errors.Is(err, nt.ErrExtendedPath) {
+				t.Errorf("ToExtendedLength(%q) returned unexpected error: %v", input, err)
+			}
+			return "error"
+		}
+		// 必须可以转换回原来的路径
+		if nt.IsDevicePath(input) {
+			return extended.String()
+		}
+		back, err := extended.(*PureWindowsPath).FromExtendedLength()
+		if err != nil || back.String() != NewPureWindowsPath(input).String() {
+			t.Errorf("FromExtendedLength(%q) = %v, %v, want %q", extended, back, err, input)
+		}
+		return extended.String()
+	})
+}
+
+func TestPureWindowsPath_FromExtendedLength(t *testing.T) {
+	runTask(t, []struct {
+		input  string
+		output string
+	}{
+		{`\\?\C:\very\long`, `C:\very\long`},
+		{`\\?\C:\a\..\b`, `C:\b`},
+		{`\\?\UNC\server\share\x`, `\\server\share\x`},
+		{`\\?\unc\server\share`, `\\server\share\`},
+		{`C:\foo`, `C:\foo`},
+		{`\\.\PhysicalDrive0`, `\\.\PhysicalDrive0\`},
+		{`\\?\Volume{GUID}\dir`, "error"},
+	}, func(input string) string {
+		path, err := NewPureWindowsPath(input).FromExtendedLength()
+		if err != nil {
+			if !errors.Is(err, nt.ErrExtendedPath) {
+				t.Errorf("FromExtendedLength(%q) returned unexpected error: %v", input, err)
+			}
+			return "error"
+		}
+		return path.String()
+	})
+}
+
+func TestPureWindowsPath_Stream(t *testing.T) {
+	type output struct {
+		name, stem, suffix, stream string
+	}
+	runTask(t, []struct {
+		input  string
+		output output
+	}{
+		{`c:\foo\report.docx:Zone.Identifier`, output{"report.docx", "report", ".docx", "Zone.Identifier"}},
+		{`c:\foo\report.docx:Zone.Identifier:$DATA`, output{"report.docx", "report", ".docx", "Zone.Identifier:$DATA"}},
+		{`report.docx::$DATA`, output{"report.docx", "report", ".docx", ":$DATA"}},
+		{`c:\foo\report.docx`, output{"report.docx", "report", ".docx", ""}},
+		{`c:\`, output{"", "", "", ""}},
+	}, func(input string) output {
+		p := NewPureWindowsPath(input)
+		return output{p.Name(), p.Stem(), p.Suffix(), p.Stream()}
+	})
+}
+
+func TestPureWindowsPath_WithStream(t *testing.T) {
+	type input struct {
+		path, stream string
+	}
+	runTask(t, []struct {
+		input  input
+		output string
+	}{
+		{input{`c:\foo\report.docx`, "Zone.Identifier"}, `c:\foo\report.docx:Zone.Identifier`},
+		{input{`c:\foo\report.docx:old`, "new:$DATA"}, `c:\foo\report.docx:new:$DATA`},
+		{input{`c:\foo\report.docx:old`, ""}, `c:\foo\report.docx`},
+		{input{`\\?\C:\..\a`, "s"}, `\\?\C:\..\a:s`},
+		{input{`c:\foo\a`, "s"}, `c:\foo\a:s`},
+		{input{`c:\foo\report.docx`, "bad/name"}, "error"},
+		{input{`c:\foo\report.docx`, "s:DATA"}, "error"},
+		{input{`c:\`, "s"}, "error"},
+	}, func(in input) string {
+		p, err := NewPureWindowsPath(in.path).WithStream(in.stream)
+		if err != nil {
+			return "error"
+		}
+		return p.String()
+	})
+}
+
+func TestPureWindowsPath_WithXKeepsStream(t *testing.T) {
+	p := NewPureWindowsPath(`c:\foo\report.docx:Zone.Identifier`)
+	runTask(t, []struct {
+		input  IPurePath
+		output string
+	}{
+		{p.MustWithName("notes.txt"), `c:\foo\notes.txt:Zone.Identifier`},
+		{p.MustWithStem("summary"), `c:\foo\summary.docx:Zone.Identifier`},
+		{p.MustWithSuffix(".pdf"), `c:\foo\report.pdf:Zone.Identifier`},
+		{p.MustWithParent(NewPureWindowsPath(`d:\`)), `d:\report.docx:Zone.Identifier`},
+	}, func(input IPurePath) string {
+		return input.String()
+	})
+}
+
+func TestPureWindowsPath_Equal(t *testing.T) {
+	type input struct {
+		a, b IPurePath
+	}
+	runTask(t, []struct {
+		input  input
+		output int
+	}{
+		{input{NewWindows(`C:\Src`), NewWindows(`c:\src`)}, 0},
+		{input{NewWindows(`\\Server\Share\A`), NewWindows(`//server/share/a`)}, 0},
+		{input{NewWindows(`c:\src`), NewWindows(`c:\src\a`)}, -1},
+		{input{NewWindows(`c:\src\B`), NewWindows(`c:\src\a`)}, 1},
+		{input{NewWindows(`c:\a\b`), NewWindows(`c:\a.b`)}, -1}, // 按组件比较，而不是按字符串比较
+		{input{NewWindows(`a\b`), NewPosix(`a/b`)}, -1},         // 不同风格
+		{input{NewWindows(`a\b`), nil}, 1},                      // nil排在所有路径之前
+	}, func(in input) int {
+		c := in.a.Compare(in.b)
+		if in.a.Equal(in.b) != (c == 0) || (in.b != nil && (in.a.Key() == in.b.Key()) != (c == 0)) {
+			t.Errorf("Equal/Key of %q and %q inconsistent with Compare %d", in.a, in.b, c)
+		}
+		return c
+	})
+}
+
+func TestPureWindowsPath_IsAncestorOf(t *testing.T) {
+	type input struct {
+		a, b string
+	}
+	runTask(t, []struct {
+		input  input
+		output bool
+	}{
+		{input{`c:\foo`, `C:\FOO\bar`}, true},
+		{input{`c:\`, `c:\foo`}, true},
+		{input{`c:\foo`, `c:\foobar`}, false},
+		{input{`c:\foo`, `c:\foo`}, false}, // 路径本身不算作祖先
+		{input{`c:`, `c:foo`}, true},
+		{input{`c:\`, `c:foo`}, false},
+		{input{`.`, `foo\bar`}, true},
+		{input{`\\server\share`, `\\SERVER\share\x`}, true},
+	}, func(in input) bool {
+		a, b := NewWindows(in.a), NewWindows(in.b)
+		if a.IsAncestorOf(b) != b.IsDescendantOf(a) {
+			t.Errorf("IsAncestorOf(%q, %q) inconsistent with IsDescendantOf", in.a, in.b)
+		}
+		return a.IsAncestorOf(b)
+	})
+}
+
+func TestPureWindowsPath_CommonAncestor(t *testing.T) {
+	runTask(t, []struct {
+		input  []string
+		output string
+	}{
+		{[]string{`c:\src\a\b`, `C:\SRC\a\c`, `c:\src\a`}, `c:\src\a`},
+		{[]string{`c:\foo`, `c:\foobar`}, `c:\`},
+		{[]string{`c:\foo`, `d:\foo`}, "error"},
+		{[]string{`a\b`, `a\c`}, `a`},
+		{[]string{`a\b`, `c`}, `.`},
+		{[]string{`a\b`, `c:\a`}, "error"},
+		{[]string{`c:\foo`}, `c:\foo`},
+		{nil, "error"},
+	}, func(input []string) string {
+		var paths []IPurePath
+		for _, p := range input {
+			paths = append(paths, NewWindows(p))
+		}
+		ancestor, err := CommonPath(paths...)
+		if err != nil {
+			if !errors.Is(err, nt.ErrNotRelative) {
+				t.Errorf("CommonPath(%q) returned unexpected error: %v", input, err)
+			}
+			return "error"
+		}
+		return ancestor.String()
+	})
+}
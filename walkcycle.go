@@ -0,0 +1,58 @@
+package path
+
+import (
+	"slices"
+	"strings"
+)
+
+// WalkCycleError 遍历时再次遇到了已经访问过的目录，errors.Is(err, ErrWalkCycle) 返回true
+type WalkCycleError struct {
+	// 第一个是最早访问这个目录时的路径，最后一个是再次遇到它的路径。
+	// 出现循环时中间是循环经过的各个目录，只是从另一条路径重复访问时只有这两个路径
+	Chain []IPath
+}
+
+func (e *WalkCycleError) Error() string {
+	paths := make([]string, len(e.Chain))
+	for i, path := range e.Chain {
+		paths[i] = path.String()
+	}
+	return ErrWalkCycle.Error() + ": " + strings.Join(paths, " -> ")
+}
+
+func (e *WalkCycleError) Is(target error) bool {
+	return target == ErrWalkCycle
+}
+
+// 文件的唯一标识，POSIX上是设备号和inode，Windows上是卷序列号和文件索引，
+// 其他无法获取标识的系统上使用绝对路径
+type fileKey struct {
+	dev, ino uint64
+	path     string
+}
+
+// 当前正在访问的目录链，从遍历的根目录开始
+type dirChain struct {
+	keys  []fileKey
+	paths []IPath
+}
+
+// 返回追加一个目录后的链，不会修改原来的链
+func (c dirChain) push(key fileKey, path IPath) dirChain {
+	return dirChain{
+		keys:  append(slices.Clip(c.keys), key),
+		paths: append(slices.Clip(c.paths), path),
+	}
+}
+
+// 检查目录是否已经访问过，没有访问过时记录到visited中。path是报告给调用者的路径，跟随符号链接时是链接本身
+func visitDir(visited map[fileKey]IPath, chain dirChain, key fileKey, path IPath) error {
+	if i := slices.Index(chain.keys, key); i >= 0 { // 目录在当前的链中，出现了循环
+		return &WalkCycleError{Chain: append(slices.Clone(chain.paths[i:]), path)}
+	}
+	if first, ok := visited[key]; ok { // 从另一条路径重复访问
+		return &WalkCycleError{Chain: []IPath{first, path}}
+	}
+	visited[key] = path
+	return nil
+}
@@ -0,0 +1,80 @@
+package path
+
+import (
+	"iter"
+
+	"github.com/viocha/go-pathlib/internal/common"
+)
+
+type WalkDirsOptions struct {
+	Follow   bool // 是否跟随符号链接，默认不跟随，此时指向目录的符号链接会放在FileNames中
+	BottomUp bool // 是否自底向上产生，子目录总是在父目录之前产生，默认自顶向下
+}
+
+// WalkDirs 产生的一个目录，以及它的子目录名称和文件名称
+type WalkDirsEntry struct {
+	Dir       IPath
+	DirNames  []string // 子目录的名称，自顶向下时可以修改这个切片，只会进入修改后的子目录
+	FileNames []string // 其他子路径的名称，包括文件、符号链接等
+}
+
+// 类似Python的 Path.walk，逐个产生目录和它的子目录名称、文件名称。
+// 读取目录失败或者检测到循环时产生 (&WalkDirsEntry{Dir: dir}, err)，循环可以选择继续或者结束
+func WalkDirs(root IPath, walkDirsOptions ...WalkDirsOptions) iter.Seq2[*WalkDirsEntry, error] {
+	options := common.ParseOptional(walkDirsOptions, WalkDirsOptions{}) // 默认不跟随符号链接，自顶向下
+	return func(yield func(*WalkDirsEntry, error) bool) {
+		walkDirs(root, options, make(map[fileKey]IPath), dirChain{}, yield)
+	}
+}
+
+// 遍历dir，visited和chain按照文件标识记录已经访问过的目录和当前的目录链，用于检测循环，返回false表示循环已经结束
+func walkDirs(dir IPath, options WalkDirsOptions, visited map[fileKey]IPath, chain dirChain,
+	yield func(*WalkDirsEntry, error) bool) bool {
+	target := dir
+	if options.Follow && dir.IsLink() {
+		var err error
+		if target, err = dir.Resolve(); err != nil {
+			return yield(&WalkDirsEntry{Dir: dir}, err)
+		}
+	}
+	key, err := fileKeyOf(target)
+	if err != nil {
+		return yield(&WalkDirsEntry{Dir: dir}, err)
+	}
+	if err := visitDir(visited, chain, key, dir); err != nil {
+		return yield(&WalkDirsEntry{Dir: dir}, err)
+	}
+	chain = chain.push(key, dir)
+
+	children, err := dir.ReadDir()
+	if err != nil {
+		return yield(&WalkDirsEntry{Dir: dir}, err)
+	}
+	entry := &WalkDirsEntry{Dir: dir}
+	dirs := make(map[string]IPath) // 子目录的名称对应的目录项，进入子目录时使用缓存的文件信息
+	for _, child := range children {
+		if child.IsDir(options.Follow) {
+			dirs[child.Name()] = child
+			entry.DirNames = append(entry.DirNames, child.Name())
+		} else {
+			entry.FileNames = append(entry.FileNames, child.Name())
+		}
+	}
+
+	if !options.BottomUp && !yield(entry, nil) {
+		return false
+	}
+	for _, name := range entry.DirNames { // 自顶向下时，循环中可能修改了DirNames
+		child, ok := dirs[name]
+		if !ok { // 循环中添加的名称
+			child = dir.Join(name)
+		}
+		if !walkDirs(child, options, visited, chain, yield) {
+			return false
+		}
+	}
+	if options.BottomUp {
+		return yield(entry, nil)
+	}
+	return true
+}
@@ -0,0 +1,228 @@
+package path
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/viocha/go-pathlib/internal/common"
+)
+
+// 并发遍历目录，使用 WalkOptions.Workers 个goroutine同时读取目录。fn的返回值和Walk相同，
+// WalkSkip跳过当前目录的向下遍历，WalkStop或者其他错误会终止遍历并返回这个错误。
+// fn会被并发调用，需要保证并发安全；父目录总是在它的子路径之前传给fn，但是不同目录之间的顺序不确定
+func WalkParallel(root IPath, fn func(path IPath, err error) error, walkOptions ...WalkOptions) error {
+	return WalkParallelContext(context.Background(), root, fn, walkOptions...)
+}
+
+// 和 WalkParallel 相同，ctx取消时尽快终止遍历，并返回ctx的错误
+func WalkParallelContext(ctx context.Context, root IPath, fn func(path IPath, err error) error,
+	walkOptions ...WalkOptions) error {
+	options := common.ParseOptional(walkOptions, WalkOptions{})
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	w := &parallelWalker{
+		ctx:     ctx,
+		cancel:  cancel,
+		fn:      fn,
+		options: options,
+		visited: make(map[fileKey]IPath),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	stopWaking := context.AfterFunc(ctx, w.wakeAll) // 取消时唤醒所有等待任务的goroutine
+	defer stopWaking()
+
+	// 先在当前goroutine中处理根路径
+	if err := w.visit(root, nil, options.Ignore.rootFrames(), dirChain{}); shouldStopWalk(err) {
+		return err
+	}
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.work()
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return nil
+}
+
+type parallelWalker struct {
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	fn      func(path IPath, err error) error
+	options WalkOptions
+
+	mu      sync.Mutex
+	cond    *sync.Cond        // 队列中有新的目录，或者遍历结束时通知
+	queue   []parallelTask    // 等待读取的目录，后进先出，减少队列的长度
+	pending int               // 队列中和正在读取的目录数量，为0时遍历结束
+	visited map[fileKey]IPath // 按照文件标识记录已经访问过的目录
+}
+
+// 一个等待读取的目录
+type parallelTask struct {
+	dir    IPath
+	rel    []string
+	frames []ignoreFrame
+	chain  dirChain // 从根目录到dir的目录链，包括dir
+}
+
+// 不断从队列中取出目录并读取，直到遍历结束或者被取消
+func (w *parallelWalker) work() {
+	for {
+		task, ok := w.pop()
+		if !ok {
+			return
+		}
+		err := w.readDir(task)
+		w.done()
+		if shouldStopWalk(err) {
+			w.cancel(err)
+		}
+	}
+}
+
+// 处理一个路径，如果是需要向下遍历的目录，则加入队列。chain为父目录的目录链
+func (w *parallelWalker) visit(path IPath, rel []string, frames []ignoreFrame, chain dirChain) error {
+	// 解析后的路径只用于读取文件信息和检测循环，子路径仍然位于链接下面
+	target := path
+	if w.options.Follow && path.IsLink() { // 如果跟随符号链接
+		var err error
+		if target, err = path.Resolve(); err != nil {
+			_, err = w.handleError(path, err)
+			return err
+		}
+	}
+
+	// 按照文件标识检测循环，同一个目录只会访问一次，判断类型和获取文件标识时共用缓存的文件信息
+	stat := lstatEntry(target)
+	isDir := stat.IsDir(false)
+	if isDir {
+		key, err := fileKeyOf(stat)
+		if err == nil {
+			err = w.visitDir(chain, key, path)
+		}
+		if err != nil {
+			_, err = w.handleError(path, err)
+			return err
+		}
+		chain = chain.push(key, path)
+	}
+
+	var err error
+	if len(rel) >= w.options.MinDepth {
+		err = w.fn(path, nil)
+		if shouldStopWalk(err) {
+			return err
+		}
+	}
+	if !isDir || errors.Is(err, WalkSkip) {
+		return nil
+	}
+	if w.options.Prune != nil && w.options.Prune(path) {
+		return nil
+	}
+	if w.options.MaxDepth > 0 && len(rel) >= w.options.MaxDepth {
+		return nil
+	}
+	w.push(parallelTask{dir: path, rel: rel, frames: frames, chain: chain})
+	return nil
+}
+
+// 读取目录，并处理所有的子路径
+func (w *parallelWalker) readDir(task parallelTask) error {
+	children, err := task.dir.ReadDir()
+	if err != nil {
+		_, err = w.handleError(task.dir, err)
+		return err
+	}
+	if compare := walkCompareFunc(w.options); compare != nil {
+		slices.SortFunc(children, compare)
+	}
+	frames := task.frames
+	frame, ok, err := w.options.Ignore.loadFrame(task.dir, len(task.rel))
+	if err != nil {
+		if next, err := w.handleError(task.dir, err); !next {
+			return err
+		}
+	}
+	if ok {
+		frames = pushIgnoreFrame(frames, frame)
+	}
+	for _, child := range children {
+		if w.ctx.Err() != nil { // 已经被取消
+			return nil
+		}
+		childRel := append(slices.Clip(task.rel), child.Name())
+		if len(frames) > 0 && ignoredBy(frames, childRel, child.IsDir(w.options.Follow)) {
+			continue
+		}
+		err := w.visit(child, childRel, frames, task.chain)
+		if shouldStopWalk(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *parallelWalker) handleError(path IPath, err error) (bool, error) {
+	return handleWalkError(w.options.OnError, w.fn, path, err)
+}
+
+func (w *parallelWalker) visitDir(chain dirChain, key fileKey, path IPath) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return visitDir(w.visited, chain, key, path)
+}
+
+func (w *parallelWalker) push(task parallelTask) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.queue = append(w.queue, task)
+	w.pending++
+	w.cond.Signal()
+}
+
+// 取出一个目录，没有目录时等待，遍历结束或者被取消时返回false
+func (w *parallelWalker) pop() (parallelTask, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.queue) == 0 && w.pending > 0 && w.ctx.Err() == nil {
+		w.cond.Wait()
+	}
+	if len(w.queue) == 0 || w.ctx.Err() != nil {
+		return parallelTask{}, false
+	}
+	task := w.queue[len(w.queue)-1]
+	w.queue = w.queue[:len(w.queue)-1]
+	return task, true
+}
+
+// 一个目录读取结束
+func (w *parallelWalker) done() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending--
+	if w.pending == 0 {
+		w.cond.Broadcast()
+	}
+}
+
+func (w *parallelWalker) wakeAll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cond.Broadcast()
+}
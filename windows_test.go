@@ -1,14 +1,21 @@
+//go:build windows
+
 package path
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"iter"
 	"os"
 	"path/filepath"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/viocha/go-pathlib/internal/common"
+	"github.com/viocha/go-pathlib/purepath"
 )
 
 func createTestFileTree(base string) error {
@@ -673,6 +680,254 @@ func TestWindowsPath_Copy(t *testing.T) {
 	}
 }
 
+func TestWindowsPath_CopyContext(t *testing.T) {
+	src := NewWindowsPath(`./file/dir`)
+	t.Run("progress", func(t *testing.T) {
+		dst := NewWindowsPath(`./file/progress_copy`)
+		defer func() {
+			_ = dst.Remove()
+		}()
+		var last CopyProgress
+		err := src.CopyContext(context.Background(), dst, CopyOptions{Progress: func(progress CopyProgress) {
+			last = progress
+		}})
+		if err != nil {
+			t.Fatalf("Failed to copy directory: %v", err)
+		}
+		if last.TotalFiles != 4 || last.Files != last.TotalFiles || last.Bytes != last.TotalBytes {
+			t.Errorf("Expected all 4 files to be done, got %+v", last)
+		}
+	})
+	t.Run("cancel", func(t *testing.T) {
+		dst := NewWindowsPath(`./file/canceled_copy`)
+		defer func() {
+			_ = dst.Remove()
+		}()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		files := 0
+		err := src.CopyContext(ctx, dst, CopyOptions{Progress: func(progress CopyProgress) {
+			files = progress.Files
+			if progress.Files == 1 {
+				cancel() // 第一个文件完成后取消
+			}
+		}})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		if files != 1 {
+			t.Errorf("Expected the copy to stop after 1 file, got %d", files)
+		}
+	})
+}
+
+func TestWindowsPath_CopyFilter(t *testing.T) {
+	src := NewWindowsPath(`./file/dir`)
+	dst := NewWindowsPath(`./file/filter_copy`)
+	defer func() {
+		_ = dst.Remove()
+	}()
+	t.Run("exclude and filter", func(t *testing.T) {
+		err := src.Copy(dst, CopyOptions{Exclude: []string{"sub"}, Filter: func(src IPath, info fs.FileInfo) bool {
+			return src.Name() != "b.md"
+		}})
+		if err != nil {
+			t.Fatalf("Failed to copy directory: %v", err)
+		}
+		if !dst.Join("a.md").Exists() || dst.Join("b.md").Exists(false) || dst.Join("sub").Exists(false) {
+			t.Errorf("Expected only a.md to be copied")
+		}
+	})
+	t.Run("include", func(t *testing.T) {
+		var last CopyProgress
+		err := src.Copy(dst, CopyOptions{Replace: true, Include: []string{"x.md"}, Progress: func(progress CopyProgress) {
+			last = progress
+		}})
+		if err != nil {
+			t.Fatalf("Failed to copy directory: %v", err)
+		}
+		if !dst.Join("sub", "x.md").Exists() || dst.Join("a.md").Exists(false) || dst.Join("sub", "y.md").Exists(false) {
+			t.Errorf("Expected only sub/x.md to be copied")
+		}
+		if last.TotalFiles != 1 || last.Files != 1 {
+			t.Errorf("Expected 1 file to be copied, got %+v", last)
+		}
+	})
+}
+
+func TestWindowsPath_CopyMerge(t *testing.T) {
+	src := NewWindowsPath(`./file/dir`)
+	dst := NewWindowsPath(`./file/merge_dst`)
+	defer func() {
+		_ = dst.Remove()
+	}()
+	// 目标中已经存在的目录会递归合并，即使使用 MergeModeError
+	if err := dst.Join("sub").Mkdir(); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := src.CopyMerge(dst); err != nil {
+		t.Fatalf("Failed to merge directories: %v", err)
+	}
+	if !dst.Join("a.md").Exists() || !dst.Join("sub", "y.md").Exists() {
+		t.Errorf("Expected all files to be merged")
+	}
+
+	t.Run("newer and larger", func(t *testing.T) {
+		// a.md 的内容更少但是更新，b.md 的内容更少并且更旧
+		future := time.Now().Add(time.Hour)
+		err := errors.Join(dst.Join("a.md").Write("new"), dst.Join("b.md").Write(""),
+			os.Chtimes(dst.Join("a.md").String(), time.Time{}, future))
+		if err != nil {
+			t.Fatalf("Failed to prepare target files: %v", err)
+		}
+		if err := src.CopyMerge(dst, CopyOptions{MergeMode: MergeModeNewer}); err != nil {
+			t.Fatalf("Failed to merge directories: %v", err)
+		}
+		if dst.Join("a.md").MustRead() != "new" {
+			t.Errorf("Expected the newer target file to be kept")
+		}
+		if err := src.CopyMerge(dst, CopyOptions{MergeMode: MergeModeLarger}); err != nil {
+			t.Fatalf("Failed to merge directories: %v", err)
+		}
+		if dst.Join("b.md").MustRead() != "b.md content" {
+			t.Errorf("Expected the smaller target file to be replaced")
+		}
+	})
+	t.Run("keep both", func(t *testing.T) {
+		if err := src.CopyMerge(dst, CopyOptions{MergeMode: MergeModeKeepBoth}); err != nil {
+			t.Fatalf("Failed to merge directories: %v", err)
+		}
+		if !dst.Join("a (1).md").Exists() || !dst.Join("sub", "x (1).md").Exists() {
+			t.Errorf("Expected incoming files to be renamed")
+		}
+	})
+	t.Run("callback", func(t *testing.T) {
+		var conflicts []string
+		err := src.CopyMerge(dst, CopyOptions{MergeMode: MergeModeCallback,
+			OnConflict: func(src, dst IPath) (MergeAction, error) {
+				conflicts = append(conflicts, dst.String())
+				if src.Name() == "a.md" {
+					return MergeActionReplace, nil
+				}
+				return MergeActionSkip, nil
+			}})
+		if err != nil {
+			t.Fatalf("Failed to merge directories: %v", err)
+		}
+		if dst.Join("a.md").MustRead() != "a.md content" {
+			t.Errorf("Expected a.md to be replaced")
+		}
+		if !slices.Contains(conflicts, `file\merge_dst\sub\x.md`) {
+			t.Errorf("Expected conflicts in sub directory, got %v", conflicts)
+		}
+	})
+}
+
+func TestWindowsPath_MoveMerge(t *testing.T) {
+	src := NewWindowsPath(`./file/move_src`)
+	dst := NewWindowsPath(`./file/move_dst`)
+	defer func() {
+		_ = src.Remove()
+		_ = dst.Remove()
+	}()
+	mtime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	prepare := func(t *testing.T) {
+		err := errors.Join(src.Join("a.md").Write("new"), src.Join("sub", "b.md").Write("b"),
+			dst.Join("a.md").Write("old"), os.Chtimes(src.Join("a.md").String(), time.Time{}, mtime))
+		if err != nil {
+			t.Fatalf("Failed to prepare files: %v", err)
+		}
+	}
+	t.Run("merge mode", func(t *testing.T) {
+		prepare(t)
+		if err := src.MoveMerge(dst, CopyOptions{MergeMode: MergeModeReplace}); err != nil {
+			t.Fatalf("Failed to move directories: %v", err)
+		}
+		if src.Exists(false) || dst.Join("a.md").MustRead() != "new" || !dst.Join("sub", "b.md").Exists() {
+			t.Errorf("Expected all files to be moved into the target")
+		}
+		// 没有要求保留修改时间
+		info, err := dst.Join("a.md").Lstat()
+		if err != nil {
+			t.Fatalf("Failed to read file status: %v", err)
+		}
+		if info.ModTime().Equal(mtime) {
+			t.Errorf("Expected the modification time not to be preserved, got %v", info.ModTime())
+		}
+	})
+	t.Run("preserve times", func(t *testing.T) {
+		prepare(t)
+		err := src.MoveMerge(dst, CopyOptions{MergeMode: MergeModeReplace, PreserveTimes: true})
+		if err != nil {
+			t.Fatalf("Failed to move directories: %v", err)
+		}
+		info, err := dst.Join("a.md").Lstat()
+		if err != nil {
+			t.Fatalf("Failed to read file status: %v", err)
+		}
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("Expected the modification time to be preserved, got %v", info.ModTime())
+		}
+	})
+	t.Run("filter", func(t *testing.T) {
+		prepare(t)
+		err := src.MoveMerge(dst, CopyOptions{MergeMode: MergeModeReplace, Exclude: []string{"sub"}})
+		if !errors.Is(err, ErrMove) {
+			t.Fatalf("Expected ErrMove, got %v", err)
+		}
+		// 被排除的文件仍然在源目录中
+		if !src.Join("sub", "b.md").Exists() || !src.Join("a.md").Exists() {
+			t.Errorf("Expected the source directory to be kept")
+		}
+	})
+}
+
+func TestWindowsPath_CopyPreserve(t *testing.T) {
+	src := NewWindowsPath(`./file/preserve`)
+	srcFile := src.Join("run.bat")
+	if err := srcFile.Write("echo ok"); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	defer func() {
+		_ = src.Remove()
+	}()
+	mtime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, path := range []IPath{srcFile, src} {
+		if err := os.Chtimes(path.String(), time.Time{}, mtime); err != nil {
+			t.Fatalf("Failed to change times: %v", err)
+		}
+	}
+
+	dst := NewWindowsPath(`./file/preserve_copy`)
+	defer func() {
+		_ = dst.Remove()
+	}()
+	if err := src.Copy(dst, CopyOptions{PreserveTimes: true}); err != nil {
+		t.Fatalf("Failed to copy directory: %v", err)
+	}
+	for _, path := range []IPath{dst.Join("run.bat"), dst} {
+		info, err := path.Lstat()
+		if err != nil {
+			t.Fatalf("Failed to stat %q: %v", path, err)
+		}
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("Expected modification time of %q to be %v, got %v", path, mtime, info.ModTime())
+		}
+	}
+
+	// 默认不保留元数据，目标已经存在时可以替换
+	if err := src.Copy(dst, CopyOptions{Replace: true}); err != nil {
+		t.Fatalf("Failed to copy directory: %v", err)
+	}
+	info, err := dst.Join("run.bat").Lstat()
+	if err != nil {
+		t.Fatalf("Failed to stat copied file: %v", err)
+	}
+	if info.ModTime().Equal(mtime) {
+		t.Errorf("Expected modification time not to be preserved")
+	}
+}
+
 func TestWindowsPath_Glob(t *testing.T) {
 	// 创建一个指向上级目录的符号链接
 	err := NewWindowsPath("./file/link-to-parent").Symlink(NewWindowsPath("."), true)
@@ -692,9 +947,144 @@ func TestWindowsPath_Glob(t *testing.T) {
 		}
 	})
 	t.Run("Glob with follow symlinks", func(t *testing.T) {
-		p := NewWindowsPath(`./file/link-to-parent`)
-		matches := p.MustGlob("**/*.md", GlobOptions{Follow: true, SkipOnCycle: true})
+		// 创建一个指向上级目录的符号链接，形成循环
+		loop := NewWindowsPath("./file/dir/sub/loop")
+		if err := loop.Symlink(NewWindowsPath(".."), true); err != nil {
+			t.Fatalf("Failed to create symlink %q: %v", loop, err)
+		}
+		defer loop.Remove()
+		matches := NewWindowsPath(`./file/dir`).MustGlob("**/*.md", GlobOptions{Follow: true, SkipOnCycle: true})
+		t.Logf("Matched files: %v", matches)
+		matchesStr := make([]string, len(matches))
+		for i, match := range matches {
+			matchesStr[i] = match.String()
+		}
+		if len(matches) != 4 || !slices.Contains(matchesStr, "file\\dir\\sub\\y.md") {
+			t.Errorf("Expected each markdown file exactly once, got %v", matchesStr)
+		}
+	})
+	t.Run("Glob through a followed link", func(t *testing.T) {
+		// 结果位于链接下面，而不是链接解析后的绝对路径
+		p := NewWindowsPath(`./file`)
+		for _, relative := range []bool{false, true} {
+			matches := p.MustGlob("ldir\\*.md", GlobOptions{Follow: true, Relative: relative, Sort: true})
+			matchesStr := make([]string, len(matches))
+			for i, match := range matches {
+				matchesStr[i] = match.String()
+			}
+			expected := []string{"file\\ldir\\a.md", "file\\ldir\\b.md"}
+			if relative {
+				expected = []string{"ldir\\a.md", "ldir\\b.md"}
+			}
+			if !slices.Equal(matchesStr, expected) {
+				t.Errorf("Expected %v, got %v", expected, matchesStr)
+			}
+		}
+	})
+	t.Run("Glob with compiled pattern", func(t *testing.T) {
+		pattern := purepath.MustCompilePatternWithFlavour(NewWindowsPath(".").Flavour(), "**/*.{md,txt}")
+		matches := NewWindowsPath(`./file/dir`).MustGlobPattern(pattern)
+		t.Logf("Matched files: %v", matches)
+		matchesStr := make([]string, len(matches))
+		for i, match := range matches {
+			matchesStr[i] = match.String()
+		}
+		if !slices.Contains(matchesStr, "file\\dir\\sub\\y.md") {
+			t.Errorf("Expected to find file 'file/dir/sub/y.md' in matches, but it was not found")
+		}
+	})
+	t.Run("Glob only reads reachable directories", func(t *testing.T) {
+		// 不会进入 link-to-parent，所以跟随符号链接时也不会检测到循环
+		matches := NewWindowsPath(".").MustGlob("file/dir/*.md", GlobOptions{Follow: true})
+		matchesStr := make([]string, len(matches))
+		for i, match := range matches {
+			matchesStr[i] = match.String()
+		}
+		slices.Sort(matchesStr)
+		if !slices.Equal(matchesStr, []string{"file\\dir\\a.md", "file\\dir\\b.md"}) {
+			t.Errorf("Expected only the markdown files in 'file/dir', got %v", matchesStr)
+		}
+	})
+	t.Run("Glob relative to receiver", func(t *testing.T) {
+		p := NewWindowsPath(`./file/dir`)
+		testcases := []struct {
+			matches []IPath
+			output  []string
+		}{
+			{p.MustGlob("*.md"), []string{"file\\dir\\a.md", "file\\dir\\b.md"}},
+			{p.MustRGlob("y.md"), []string{"file\\dir\\sub\\y.md"}},
+			{p.MustGlob("sub/*.md", GlobOptions{Relative: true}), []string{"sub\\x.md", "sub\\y.md"}},
+		}
+		for _, tc := range testcases {
+			matchesStr := make([]string, len(tc.matches))
+			for i, match := range tc.matches {
+				matchesStr[i] = match.String()
+			}
+			slices.Sort(matchesStr)
+			if !slices.Equal(matchesStr, tc.output) {
+				t.Errorf("Expected %v, got %v", tc.output, matchesStr)
+			}
+		}
+		if _, err := p.Glob("C:\\*.md"); !errors.Is(err, ErrGlobPattern) {
+			t.Errorf("Expected ErrGlobPattern for anchored pattern, got %v", err)
+		}
+	})
+	t.Run("Glob with options", func(t *testing.T) {
+		p := NewWindowsPath(`./file/dir`)
+		if err := p.Join(".hidden.md").Write("hidden"); err != nil {
+			t.Fatalf("Failed to create hidden file: %v", err)
+		}
+		defer p.Join(".hidden.md").Remove()
+		if err := p.Join(".hidden", "z.md").Write("hidden"); err != nil {
+			t.Fatalf("Failed to create file in hidden directory: %v", err)
+		}
+		defer p.Join(".hidden").Remove()
+		yes := true
+		testcases := []struct {
+			name    string
+			pattern string
+			options GlobOptions
+			output  []string
+		}{
+			{"only files", "**/*", GlobOptions{OnlyFiles: true}, []string{"a.md", "b.md", "sub\\x.md", "sub\\y.md"}},
+			{"only dirs", "**/*", GlobOptions{OnlyDirs: true}, []string{"sub"}},
+			{"max depth", "**/*.md", GlobOptions{MaxDepth: 1}, []string{"a.md", "b.md"}},
+			{"exclude", "**/*.md", GlobOptions{Exclude: []string{"sub", "b.*"}}, []string{"a.md"}},
+			{"include hidden", "*.md", GlobOptions{IncludeHidden: true}, []string{".hidden.md", "a.md", "b.md"}},
+			{"case sensitive", "*.MD", GlobOptions{CaseSensitive: &yes}, nil},
+			{"literal hidden file", ".hidden.md", GlobOptions{}, []string{".hidden.md"}},
+			{"literal hidden dir", ".hidden\\*", GlobOptions{}, []string{".hidden\\z.md"}},
+			{"hidden dir by wildcard", "*\\*.md", GlobOptions{}, []string{"sub\\x.md", "sub\\y.md"}},
+			{"case insensitive literal", "SUB\\X.MD", GlobOptions{}, []string{"sub\\x.md"}},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				tc.options.Relative, tc.options.Sort = true, true
+				matches := p.MustGlob(tc.pattern, tc.options)
+				var matchesStr []string
+				for _, match := range matches {
+					matchesStr = append(matchesStr, match.String())
+				}
+				if !slices.Equal(matchesStr, tc.output) {
+					t.Errorf("Expected %v, got %v", tc.output, matchesStr)
+				}
+			})
+		}
+		if _, err := p.Glob("*", GlobOptions{OnlyFiles: true, OnlyDirs: true}); !errors.Is(err, ErrGlobOptions) {
+			t.Errorf("Expected ErrGlobOptions, got %v", err)
+		}
+	})
+	t.Run("Glob with ignore", func(t *testing.T) {
+		ignore := MustNewIgnoreMatcher("", "sub/", "b.md")
+		matches := NewWindowsPath(`./file/dir`).MustGlob("**/*.md", GlobOptions{Ignore: ignore})
 		t.Logf("Matched files: %v", matches)
+		matchesStr := make([]string, len(matches))
+		for i, match := range matches {
+			matchesStr[i] = match.String()
+		}
+		if !slices.Equal(matchesStr, []string{"file\\dir\\a.md"}) {
+			t.Errorf("Expected only 'file/dir/a.md' in matches, got %v", matchesStr)
+		}
 	})
 }
 
@@ -726,7 +1116,7 @@ func TestWindowsPath_Walk(t *testing.T) {
 				return WalkSkip // 跳过子目录的向下遍历
 			}
 			return err
-		}, true)
+		}, WalkOptions{Follow: true})
 		if err != nil {
 			t.Fatalf("Failed to walk path: %v", err)
 		}
@@ -743,9 +1133,196 @@ func TestWindowsPath_Walk(t *testing.T) {
 				return WalkStop
 			}
 			return err
-		}, true)
+		}, WalkOptions{Follow: true})
 		if !errors.Is(err, WalkStop) {
 			t.Fatalf("Failed to walk path: %v", err)
 		}
 	})
+	t.Run("Walk with depth and sort", func(t *testing.T) {
+		collect := func(options WalkOptions) []string {
+			var result []string
+			err := NewWindowsPath(`./file/dir`).Walk(func(path IPath, err error) error {
+				result = append(result, path.String())
+				return err
+			}, options)
+			if err != nil {
+				t.Fatalf("Failed to walk path: %v", err)
+			}
+			return result
+		}
+		expected := []string{`file\dir\a.md`, `file\dir\b.md`, `file\dir\sub`}
+		if result := collect(WalkOptions{MinDepth: 1, MaxDepth: 1, Sort: true}); !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+		reverse := func(a, b IPath) int { return b.Compare(a) }
+		expected = []string{`file\dir\sub`, `file\dir\sub\y.md`, `file\dir\sub\x.md`, `file\dir\b.md`, `file\dir\a.md`}
+		if result := collect(WalkOptions{MinDepth: 1, SortFunc: reverse}); !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+	})
+	t.Run("Walk with OnError", func(t *testing.T) {
+		var skipped []string
+		err := p.Walk(func(path IPath, err error) error {
+			if err != nil {
+				t.Errorf("Error should not be passed to fn: %v", err)
+			}
+			return err
+		}, WalkOptions{Follow: true, OnError: func(path IPath, err error) WalkErrorAction {
+			skipped = append(skipped, path.Name())
+			return WalkErrorSkip // 跳过不存在的链接目标和重复访问的目录
+		}})
+		if err != nil {
+			t.Fatalf("Failed to walk path: %v", err)
+		}
+		if !slices.Contains(skipped, "lnodir") || !slices.Contains(skipped, "ldir") {
+			t.Errorf("Expected lnodir and ldir to be skipped, got %v", skipped)
+		}
+		err = p.Walk(func(path IPath, err error) error {
+			return err
+		}, WalkOptions{Follow: true, OnError: func(path IPath, err error) WalkErrorAction {
+			return WalkErrorAbort
+		}})
+		if err == nil {
+			t.Errorf("Expected the walk to be aborted")
+		}
+	})
+}
+
+func TestWindowsPath_Iter(t *testing.T) {
+	p := NewWindowsPath(`./file/dir`)
+	collect := func(seq iter.Seq2[IPath, error]) []string {
+		var result []string
+		for path, err := range seq {
+			if err != nil {
+				t.Fatalf("Failed to iterate: %v", err)
+			}
+			result = append(result, path.String())
+		}
+		slices.Sort(result)
+		return result
+	}
+	t.Run("IterDir", func(t *testing.T) {
+		expected := []string{"file\\dir\\a.md", "file\\dir\\b.md", "file\\dir\\sub"}
+		if result := collect(p.IterDir()); !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+	})
+	t.Run("IterWalk with prune", func(t *testing.T) {
+		prune := func(dir IPath) bool { return dir.Name() == "sub" }
+		expected := []string{"file\\dir", "file\\dir\\a.md", "file\\dir\\b.md", "file\\dir\\sub"}
+		if result := collect(p.IterWalk(WalkOptions{Prune: prune})); !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+	})
+	t.Run("IterWalk with break", func(t *testing.T) {
+		count := 0
+		for range p.IterWalk() {
+			count++
+			break
+		}
+		if count != 1 {
+			t.Errorf("Expected the walk to stop after 1 path, got %d", count)
+		}
+	})
+	t.Run("IterGlob", func(t *testing.T) {
+		expected := []string{"file\\dir\\sub\\x.md", "file\\dir\\sub\\y.md"}
+		if result := collect(p.IterGlob("sub/*.md")); !slices.Equal(result, expected) {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+		for path, err := range p.IterGlob("[") {
+			if !errors.Is(err, purepath.ErrInvalidPattern) {
+				t.Errorf("Expected ErrInvalidPattern for %q, got %v", path, err)
+			}
+		}
+	})
+}
+
+func TestWindowsPath_WalkDirs(t *testing.T) {
+	p := NewWindowsPath(`./file/dir`)
+	t.Run("top down with prune", func(t *testing.T) {
+		var dirs []string
+		for entry, err := range p.WalkDirs() {
+			if err != nil {
+				t.Fatalf("Failed to walk dirs: %v", err)
+			}
+			dirs = append(dirs, entry.Dir.String())
+			if !slices.Equal(entry.DirNames, []string{"sub"}) || !slices.Equal(entry.FileNames, []string{"a.md", "b.md"}) {
+				t.Errorf("Unexpected entry: %+v", entry)
+			}
+			entry.DirNames = nil // 不进入子目录
+		}
+		if !slices.Equal(dirs, []string{"file\\dir"}) {
+			t.Errorf("Expected only 'file/dir', got %v", dirs)
+		}
+	})
+	t.Run("bottom up", func(t *testing.T) {
+		var dirs []string
+		for entry, err := range p.WalkDirs(WalkDirsOptions{BottomUp: true}) {
+			if err != nil {
+				t.Fatalf("Failed to walk dirs: %v", err)
+			}
+			dirs = append(dirs, entry.Dir.String())
+		}
+		if !slices.Equal(dirs, []string{"file\\dir\\sub", "file\\dir"}) {
+			t.Errorf("Expected sub directory before its parent, got %v", dirs)
+		}
+	})
+	t.Run("follow symlinks", func(t *testing.T) {
+		var chain []string
+		for entry, err := range NewWindowsPath(`./file`).WalkDirs(WalkDirsOptions{Follow: true}) {
+			var cycleErr *WalkCycleError
+			if entry.Dir.Name() == "ldir" && errors.As(err, &cycleErr) {
+				for _, path := range cycleErr.Chain {
+					chain = append(chain, path.String())
+				}
+			}
+		}
+		// ldir 指向已经访问过的 dir，不会重复访问
+		if !slices.Equal(chain, []string{"file\\dir", "file\\ldir"}) {
+			t.Errorf("Expected ldir to be reported as a revisit of dir, got %v", chain)
+		}
+	})
+
+
+}
+
+func TestWindowsPath_ReadDirEntries(t *testing.T) {
+	entries := NewWindowsPath(`./file`).MustReadDir()
+	byName := make(map[string]*Entry)
+	for _, path := range entries {
+		entry, ok := path.(*Entry)
+		if !ok {
+			t.Fatalf("Expected *Entry, got %T", path)
+		}
+		byName[entry.Name()] = entry
+	}
+	testcases := []struct {
+		name     string
+		isLink   bool
+		isDir    bool // 不跟随符号链接
+		isDirF   bool // 跟随符号链接
+		exists   bool // 跟随符号链接
+		typeMode fs.FileMode
+	}{
+		{"f.md", false, false, false, true, 0},
+		{"dir", false, true, true, true, fs.ModeDir},
+		{"ldir", true, false, true, true, fs.ModeSymlink},
+		{"lnodir", true, false, false, false, fs.ModeSymlink},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := byName[tc.name]
+			if entry == nil {
+				t.Fatalf("Entry %q not found", tc.name)
+			}
+			if entry.IsLink() != tc.isLink || entry.IsDir(false) != tc.isDir || entry.IsDir() != tc.isDirF ||
+				entry.Exists() != tc.exists || !entry.Exists(false) || entry.Type() != tc.typeMode {
+				t.Errorf("Unexpected entry state for %q", tc.name)
+			}
+			info, err := entry.Info()
+			if err != nil || info.Name() != tc.name {
+				t.Errorf("Failed to get info of %q: %v", tc.name, err)
+			}
+		})
+	}
 }
@@ -0,0 +1,84 @@
+//go:build linux
+
+package path
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+)
+
+// 复制所有的扩展属性，src不支持扩展属性时不做任何事情
+func copyXattrs(src, dst IPath) error {
+	names, err := listXattrs(src.String())
+	if err != nil {
+		if errors.Is(err, syscall.ENOTSUP) {
+			return nil
+		}
+		return err
+	}
+	for _, name := range names {
+		value, err := getXattr(src.String(), name)
+		if err != nil {
+			return err
+		}
+		if err := syscall.Setxattr(dst.String(), name, value, 0); err != nil && !ignorableXattrError(name, err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// 设置扩展属性失败时是否可以忽略，和 cp -a 相同：dst不支持扩展属性，
+// 或者没有权限设置 security.* 和 trusted.* 等特权命名空间的属性（通常是因为不是root用户）
+func ignorableXattrError(name string, err error) bool {
+	if errors.Is(err, syscall.ENOTSUP) {
+		return true
+	}
+	return errors.Is(err, syscall.EPERM) && (strings.HasPrefix(name, "security.") || strings.HasPrefix(name, "trusted."))
+}
+
+// 扩展属性的名称列表，内核返回以 \0 分隔的名称
+func listXattrs(path string) ([]string, error) {
+	buf, err := readXattr(func(dest []byte) (int, error) {
+		return syscall.Listxattr(path, dest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range strings.SplitSeq(string(buf), "\x00") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	return readXattr(func(dest []byte) (int, error) {
+		return syscall.Getxattr(path, name, dest)
+	})
+}
+
+// 先查询需要的大小再读取，读取之间大小发生变化时重试
+func readXattr(read func(dest []byte) (int, error)) ([]byte, error) {
+	for {
+		size, err := read(nil)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size)
+		n, err := read(buf)
+		if errors.Is(err, syscall.ERANGE) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package path
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestIgnorableXattrError(t *testing.T) {
+	testcases := []struct {
+		name   string
+		err    error
+		output bool
+	}{
+		{"user.comment", syscall.ENOTSUP, true},
+		{"security.selinux", syscall.EPERM, true},
+		{"trusted.overlay", syscall.EPERM, true},
+		{"user.comment", syscall.EPERM, false},
+		{"security.selinux", syscall.EACCES, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := ignorableXattrError(tc.name, tc.err); result != tc.output {
+				t.Errorf("Expected %v, got %v", tc.output, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package path
+
+// 只支持Linux的扩展属性，其他系统上不做任何事情
+func copyXattrs(src, dst IPath) error {
+	return nil
+}